@@ -9,6 +9,7 @@ import (
 	"gorm.io/gorm"
 
 	"OpsGo/internal/domain/entity/devops"
+	devops_repo "OpsGo/internal/infrastructure/repository/devops"
 )
 
 func main() {
@@ -31,10 +32,32 @@ func main() {
 	err = db.AutoMigrate(
 		&devops.RepoConfig{},
 		&devops.PipelineRecord{},
+		&devops.PipelineAttempt{},
+		&devops.FreezeWindow{},
+		&devops.DeploySchedule{},
+		&devops.PipelineArtifact{},
+		&devops.Release{},
+		&devops.NotificationChannel{},
+		&devops.NotificationRule{},
+		&devops.CommitStatusReport{},
+		&devops.PipelineLogIndex{},
+		&devops.RetentionRun{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to migrate schema: %v", err)
 	}
+	if err := devops_repo.MigrateLogSearch(db); err != nil {
+		log.Fatalf("Failed to create log search index: %v", err)
+	}
+
+	// 4. Move the logs stored in commit_msg by older versions
+	moved, err := devops_repo.MigratePipelineLogs(db)
+	if err != nil {
+		log.Fatalf("Failed to migrate pipeline logs: %v", err)
+	}
+	if moved > 0 {
+		log.Printf("Moved the logs of %d pipeline(s) out of commit_msg.", moved)
+	}
 
-	log.Println("Migration complete! Tables 'devops_repo_configs' and 'devops_pipeline_records' are ready.")
+	log.Println("Migration complete! DevOps tables are ready.")
 }
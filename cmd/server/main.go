@@ -10,6 +10,7 @@ import (
 	devopsHandler "OpsGo/internal/interfaces/http/handler/devops"
 	monitorHandler "OpsGo/internal/interfaces/http/handler/monitor"
 	"OpsGo/internal/interfaces/http/middleware"
+	"expvar"
 	"fmt"
 	"log"
 
@@ -41,6 +42,22 @@ func main() {
 
 	// 4. Initialize Services
 	devopsService := devops.NewDevOpsService(devopsRepo)
+	notificationService := devops.NewNotificationService(devopsRepo)
+	devopsService.OnStatusChange(notificationService.Notify)
+	commitStatusService := devops.NewCommitStatusService(devopsRepo)
+	devopsService.OnStatusChange(commitStatusService.Report)
+	logSearchService := devops.NewLogSearchService(devopsRepo)
+	devopsService.OnStatusChange(logSearchService.IndexPipeline)
+	logSearchService.StartIndexer()
+	defer logSearchService.Stop()
+	devopsService.StartApprovalReaper()
+	devopsService.StartFreezeQueue()
+	devopsService.StartScheduler()
+	devopsService.StartArtifactJanitor()
+	devopsService.StartRetention()
+	defer devopsService.Stop()
+
+	statsService := devops.NewStatsService(devopsRepo)
 
 	// Monitor Service
 	monitorService := monitor.NewMonitorService()
@@ -51,12 +68,20 @@ func main() {
 
 	// 5. Initialize Handlers
 	devOpsH := devopsHandler.NewDevOpsHandler(devopsService)
+	statsH := devopsHandler.NewStatsHandler(statsService)
+	notificationH := devopsHandler.NewNotificationHandler(notificationService)
+	commitStatusH := devopsHandler.NewCommitStatusHandler(commitStatusService)
+	searchH := devopsHandler.NewSearchHandler(logSearchService)
 	monitorH := monitorHandler.NewMonitorHandler(monitorService)
 
 	// 6. Setup Router
 	r := gin.Default()
 	r.Use(middleware.CORS()) // Ensure CORS is enabled for 8080/8081 cross-origin
 
+	// Runtime metrics (expvar), e.g. devops_broadcaster drop counts; they
+	// include the command line and memory statistics, so admins only
+	r.GET("/debug/vars", middleware.Auth(), middleware.RequireRole("admin"), gin.WrapH(expvar.Handler()))
+
 	// Health Check
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "OpsGo is running"})
@@ -67,12 +92,59 @@ func main() {
 	{
 		// Public SSE Route
 		v1.GET("/events", devOpsH.StreamLogs)
+		v1.GET("/ws", middleware.OptionalAuth(), devOpsH.StreamWebSocket)
 
 		v1.POST("/config", devOpsH.ConfigRepo)
 		v1.DELETE("/config/:id", devOpsH.DeleteConfig)
 		v1.GET("/summary", devOpsH.GetSummary)
-		v1.POST("/deploy", devOpsH.TriggerDeployment)
+		v1.GET("/stats", statsH.GetStats)
+		v1.GET("/services/:id/releases", devOpsH.ListReleases)
+		v1.GET("/services/:id/current", devOpsH.GetCurrentRelease)
+		v1.GET("/services/:id/logs/export", devOpsH.ExportServiceLogs)
+		v1.POST("/deploy", middleware.Auth(), devOpsH.TriggerDeployment)
+		v1.GET("/pipelines", devOpsH.ListPipelines)
+		v1.GET("/search", searchH.SearchLogs)
+		v1.GET("/pipelines/:id", devOpsH.GetPipeline)
+		v1.GET("/pipelines/:id/logs/download", devOpsH.DownloadPipelineLog)
+		v1.GET("/pipelines/:id/changelog", devOpsH.GetChangelog)
+		v1.GET("/pipelines/:id/commit-statuses", commitStatusH.ListReports)
+		v1.POST("/pipelines/:id/rerun", middleware.Auth(), devOpsH.RerunPipeline)
+		v1.POST("/pipelines/:id/rollback", middleware.Auth(), devOpsH.RollbackToPipeline)
+		v1.POST("/pipelines/:id/artifacts", devOpsH.UploadArtifact)
+		v1.GET("/pipelines/:id/artifacts", devOpsH.ListArtifacts)
+		v1.GET("/pipelines/:id/artifacts/:artifact_id/download", devOpsH.DownloadArtifact)
+
+		approvers := v1.Group("", middleware.Auth(), middleware.RequireRole("approver"))
+		approvers.POST("/pipelines/:id/approve", devOpsH.ApprovePipeline)
+		approvers.POST("/pipelines/:id/reject", devOpsH.RejectPipeline)
+
+		// Schedules deploy like /deploy does, so they need the same token.
+		schedules := v1.Group("/schedules", middleware.Auth())
+		schedules.GET("", devOpsH.ListSchedules)
+		schedules.POST("", devOpsH.CreateSchedule)
+		schedules.POST("/:id/pause", devOpsH.PauseSchedule)
+		schedules.POST("/:id/resume", devOpsH.ResumeSchedule)
+		schedules.DELETE("/:id", devOpsH.DeleteSchedule)
+
+		v1.GET("/freezes", devOpsH.ListFreezeWindows)
+		admins := v1.Group("", middleware.Auth(), middleware.RequireRole("admin"))
+		admins.POST("/freezes", devOpsH.SaveFreezeWindow)
+		admins.DELETE("/freezes/:id", devOpsH.DeleteFreezeWindow)
+		admins.POST("/deploy/override", devOpsH.TriggerDeploymentOverride)
+		admins.POST("/config/:id/forge", devOpsH.SaveForgeSettings)
+		admins.GET("/notifications/channels", notificationH.ListChannels)
+		admins.POST("/notifications/channels", notificationH.SaveChannel)
+		admins.DELETE("/notifications/channels/:id", notificationH.DeleteChannel)
+		admins.POST("/notifications/channels/:id/test", notificationH.TestChannel)
+		admins.POST("/notifications/preview", notificationH.PreviewNotification)
+		admins.GET("/notifications/rules", notificationH.ListRules)
+		admins.POST("/notifications/rules", notificationH.SaveRule)
+		admins.DELETE("/notifications/rules/:id", notificationH.DeleteRule)
+		admins.GET("/retention/runs", devOpsH.ListRetentionRuns)
+		admins.POST("/retention/run", devOpsH.RunRetention)
 		v1.GET("/logs/:id", devOpsH.GetServiceLog)
+		v1.GET("/logs/:id/stream", devOpsH.StreamServiceLog)
+		v1.GET("/logs/:id/sources", devOpsH.ListLogSources)
 
 		v1.GET("/monitor/stats", monitorH.GetStats)
 
@@ -2,8 +2,8 @@ package dto
 
 // PageRequest 分页请求
 type PageRequest struct {
-	Page     int `json:"page" form:"page" binding:"min=1"`
-	PageSize int `json:"page_size" form:"page_size" binding:"min=1,max=100"`
+	Page     int `json:"page" form:"page,default=1" binding:"min=1"`
+	PageSize int `json:"page_size" form:"page_size,default=10" binding:"min=1,max=100"`
 }
 
 // GetOffset 获取偏移量
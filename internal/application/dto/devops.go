@@ -1,35 +1,102 @@
 package dto
 
-import "time"
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"time"
+)
 
 type ConfigRepoRequest struct {
-	RepoURL      string `json:"repo_url" binding:"required"`
-	DeployScript string `json:"deploy_script" binding:"required"`
-	Name         string `json:"name" binding:"required"`
-	LogPath      string `json:"log_path"`
+	RepoURL         string                   `json:"repo_url" binding:"required"`
+	DeployScript    string                   `json:"deploy_script" binding:"required"`
+	Name            string                   `json:"name" binding:"required"`
+	LogPath         string                   `json:"log_path"`
+	LogSources      []devops.LogSource       `json:"log_sources"`
+	HealthCheck     devops.HealthCheckPolicy `json:"health_check"`
+	Retry           devops.RetryPolicy       `json:"retry"`
+	RequireApproval bool                     `json:"require_approval"`
+	ApprovalTimeout int                      `json:"approval_timeout"` // minutes
+}
+
+// ForgeRequest 提交状态回写配置，token 留空且 type、api_url 不变时保留原值
+type ForgeRequest struct {
+	Type    string `json:"type" binding:"omitempty,oneof=github gitea"`
+	APIURL  string `json:"api_url"`
+	Token   string `json:"token"`
+	Context string `json:"context"`
 }
 
 type ConfigRepoResponse struct {
-	ID           uint64 `json:"id"`
-	RepoURL      string `json:"repo_url"`
-	DeployScript string `json:"deploy_script"`
-	Name         string `json:"name"`
-	LogPath      string `json:"log_path"`
+	ID              uint64                   `json:"id"`
+	RepoURL         string                   `json:"repo_url"`
+	DeployScript    string                   `json:"deploy_script"`
+	Name            string                   `json:"name"`
+	LogPath         string                   `json:"log_path"`
+	LogSources      []devops.LogSource       `json:"log_sources"`
+	HealthCheck     devops.HealthCheckPolicy `json:"health_check"`
+	Retry           devops.RetryPolicy       `json:"retry"`
+	RequireApproval bool                     `json:"require_approval"`
+	ApprovalTimeout int                      `json:"approval_timeout"` // minutes
+	Forge           devops.ForgeSettings     `json:"forge"`
 }
 
 type PipelineRecordResponse struct {
-	ID            uint64     `json:"id"`
-	RepoName      string     `json:"repo_name"`
-	Status        string     `json:"status"`
-	Ref           string     `json:"ref"`
-	CommitSHA     string     `json:"commit_sha"`
-	CommitMsg     string     `json:"commit_msg"`
-	Author        string     `json:"author"`
-	TriggerSource string     `json:"trigger_source"`
-	Duration      int64      `json:"duration"`
-	StartedAt     *time.Time `json:"started_at"`
-	FinishedAt    *time.Time `json:"finished_at"`
-	CreatedAt     time.Time  `json:"created_at"`
+	ID             uint64                   `json:"id"`
+	RepoName       string                   `json:"repo_name"`
+	Status         string                   `json:"status"`
+	Ref            string                   `json:"ref"`
+	CommitSHA      string                   `json:"commit_sha"`
+	CommitMsg      string                   `json:"commit_msg"`
+	Author         string                   `json:"author"`
+	TriggerSource  string                   `json:"trigger_source"`
+	TriggeredBy    string                   `json:"triggered_by"`
+	RollbackOf     uint64                   `json:"rollback_of"`
+	RerunOf        uint64                   `json:"rerun_of"`
+	ScheduleID     uint64                   `json:"schedule_id"`
+	Args           []string                 `json:"args"`
+	Env            map[string]string        `json:"env,omitempty"` // 仅在详情中返回
+	Approval       devops.PipelineApproval  `json:"approval"`
+	FreezeOverride devops.FreezeOverride    `json:"freeze_override"`
+	ChangelogFrom  string                   `json:"changelog_from"`
+	Changelog      []devops.ChangelogCommit `json:"changelog"`
+	Attempts       int                      `json:"attempts"`
+	Log            string                   `json:"log,omitempty"` // 仅在详情中返回
+	Duration       int64                    `json:"duration"`
+	StartedAt      *time.Time               `json:"started_at"`
+	FinishedAt     *time.Time               `json:"finished_at"`
+	CreatedAt      time.Time                `json:"created_at"`
+}
+
+// PipelineListRequest 流水线历史查询条件
+type PipelineListRequest struct {
+	PageRequest
+	ServiceID     uint64 `form:"service_id"`
+	Status        string `form:"status"` // comma separated
+	TriggerSource string `form:"trigger_source"`
+	Ref           string `form:"ref"`
+	Author        string `form:"author"`
+	From          string `form:"from"` // RFC3339 or 2006-01-02
+	To            string `form:"to"`
+	Sort          string `form:"sort,default=created_at" binding:"oneof=id created_at started_at finished_at duration"`
+	Order         string `form:"order,default=desc" binding:"oneof=asc desc"`
+}
+
+type PipelineAttemptResponse struct {
+	Attempt    int        `json:"attempt"`
+	ExitCode   int        `json:"exit_code"`
+	LogSize    int        `json:"log_size"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// PipelineDetailResponse 单条流水线详情
+type PipelineDetailResponse struct {
+	Pipeline       PipelineRecordResponse    `json:"pipeline"`
+	Config         *ConfigRepoResponse       `json:"config"`          // config at trigger time, or the current one for older pipelines
+	ConfigSnapshot bool                      `json:"config_snapshot"` // false when Config is the current config
+	Attempts       []PipelineAttemptResponse `json:"attempts"`
+	LogSize        int                       `json:"log_size"`
+	TriggeredBy    string                    `json:"triggered_by"`
+	Links          map[string]string         `json:"links"`
 }
 
 type DevOpsSummaryResponse struct {
@@ -46,9 +113,232 @@ type WebhookPayload struct {
 	Status    string `json:"status"`
 }
 
+type TriggerDeploymentRequest struct {
+	ConfigID uint64            `json:"config_id" binding:"required"`
+	Ref      string            `json:"ref"` // branch or tag, defaults to "latest"
+	Env      map[string]string `json:"env"` // 变量名须以 OPSGO_VAR_ 开头
+}
+
+type OverrideDeploymentRequest struct {
+	TriggerDeploymentRequest
+	Reason string `json:"reason" binding:"required"`
+}
+
+type FreezeWindowRequest struct {
+	ID       uint64 `json:"id"`
+	Name     string `json:"name" binding:"required"`
+	ConfigID uint64 `json:"config_id"` // 0 applies to every service
+	Cron     string `json:"cron"`
+	Duration int    `json:"duration"` // minutes
+	StartAt  string `json:"start_at"` // RFC3339 or "2006-01-02 15:04" in Timezone
+	EndAt    string `json:"end_at"`
+	Timezone string `json:"timezone"`
+	Action   string `json:"action"` // reject (default), queue
+	Enabled  *bool  `json:"enabled"`
+}
+
+type ScheduleRequest struct {
+	ConfigID uint64            `json:"config_id" binding:"required"`
+	Name     string            `json:"name"`
+	Cron     string            `json:"cron"`   // recurring, e.g. "0 2 * * *"
+	RunAt    string            `json:"run_at"` // one-shot, RFC3339 or "2006-01-02 15:04" in Timezone
+	Timezone string            `json:"timezone"`
+	Ref      string            `json:"ref"` // defaults to "latest"
+	Env      map[string]string `json:"env"` // 变量名须以 OPSGO_VAR_ 开头
+}
+
+// ServiceLogRequest 按行读取服务日志，从末尾向前分页
+type ServiceLogRequest struct {
+	Source string `form:"source"` // 日志源名称，默认为 default（即 log_path）
+	Lines  int    `form:"lines,default=200" binding:"min=1,max=5000"`
+	File   int    `form:"file" binding:"min=0"`             // 0 为当前文件，1 为 app.log.1 或 app.log.1.gz，依此类推
+	Before *int64 `form:"before" binding:"omitempty,min=0"` // 字节偏移，只读取之前开始的行，默认文件末尾
+	Cursor string `form:"cursor"`                           // journald：只读取该条目之前的日志
+	Offset int    `form:"offset" binding:"min=0"`           // 跳过末尾（或 before 之前）的匹配行数
+	Grep   string `form:"grep"`                             // 正则过滤
+	Since  string `form:"since"`                            // RFC3339 或 2006-01-02，按行首时间过滤
+	Until  string `form:"until"`
+}
+
+// ServiceLogLine 服务日志中的一行
+type ServiceLogLine struct {
+	File   string     `json:"file,omitempty"`
+	Offset int64      `json:"offset"` // 行首在（解压后）文件中的字节偏移
+	Time   *time.Time `json:"time,omitempty"`
+	Text   string     `json:"text"`
+}
+
+// ServiceLogCursor 下一页（更早的日志）的位置
+type ServiceLogCursor struct {
+	File   int    `json:"file"`
+	Before int64  `json:"before"`
+	Cursor string `json:"cursor,omitempty"` // journald 日志源
+}
+
+// ServiceLogResponse 服务日志的一页，按时间正序
+type ServiceLogResponse struct {
+	Files []string          `json:"files"` // 当前文件及其轮转文件，file 参数即其下标
+	Lines []ServiceLogLine  `json:"lines"`
+	Next  *ServiceLogCursor `json:"next"` // 没有更早的日志时为空
+}
+
+// ServiceLogStreamRequest 实时跟踪服务日志
+type ServiceLogStreamRequest struct {
+	Source string `form:"source"`
+	Lines  int    `form:"lines,default=10" binding:"min=0,max=5000"` // 从末尾之前多少行开始
+}
+
+type ApprovalDecisionRequest struct {
+	Comment string `json:"comment" binding:"required"`
+}
+
 type CICallbackRequest struct {
 	RepoURL   string `json:"repo_url" binding:"required"`
 	Status    string `json:"status" binding:"required"`
 	Tag       string `json:"tag"`
 	CommitSHA string `json:"commit_sha"`
 }
+
+// StatsRequest 部署统计查询条件
+type StatsRequest struct {
+	ServiceID uint64 `form:"service_id"`
+	From      string `form:"from"` // RFC3339 or 2006-01-02, defaults to 30 days ago
+	To        string `form:"to"`
+	Bucket    string `form:"bucket,default=day" binding:"oneof=day week"`
+}
+
+// DeploymentStats 一组部署的 DORA 指标，时长单位为秒
+type DeploymentStats struct {
+	Total               int     `json:"total"` // finished deploys: success, failed, unhealthy
+	Succeeded           int     `json:"succeeded"`
+	Failed              int     `json:"failed"`
+	SuccessRate         float64 `json:"success_rate"`
+	ChangeFailureRate   float64 `json:"change_failure_rate"`
+	DeploymentFrequency float64 `json:"deployment_frequency"` // successful deploys per day
+	LeadTimeAvg         float64 `json:"lead_time_avg"`        // trigger to finish of successful deploys
+	LeadTimeP50         float64 `json:"lead_time_p50"`
+	MTTR                float64 `json:"mttr"` // first failure to next success
+	Recoveries          int     `json:"recoveries"`
+	DurationP50         float64 `json:"duration_p50"`
+	DurationP95         float64 `json:"duration_p95"`
+}
+
+type StatsBucket struct {
+	Start time.Time `json:"start"`
+	DeploymentStats
+}
+
+type ServiceStats struct {
+	ConfigID uint64 `json:"config_id"`
+	Name     string `json:"name"`
+	DeploymentStats
+	Buckets []StatsBucket `json:"buckets"`
+}
+
+type StatsResponse struct {
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	Bucket   string          `json:"bucket"`
+	Global   DeploymentStats `json:"global"`
+	Buckets  []StatsBucket   `json:"buckets"`
+	Services []ServiceStats  `json:"services"`
+}
+
+// ChangelogResponse 流水线相对上一个版本部署的提交
+type ChangelogResponse struct {
+	PipelineID uint64                   `json:"pipeline_id"`
+	From       string                   `json:"from"` // 上一个版本的 SHA，首次发布为空
+	To         string                   `json:"to"`
+	Commits    []devops.ChangelogCommit `json:"commits"`
+}
+
+// NotificationChannelRequest 创建或更新通知渠道
+type NotificationChannelRequest struct {
+	ID         uint64   `json:"id"`
+	Name       string   `json:"name" binding:"required"`
+	Type       string   `json:"type" binding:"required,oneof=webhook slack dingtalk feishu email"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"` // 更新时留空则保留原值
+	Recipients []string `json:"recipients"`
+	Template   string   `json:"template"` // Go text/template，为空使用默认格式
+	Enabled    *bool    `json:"enabled"`
+}
+
+// NotificationRuleRequest 将服务的流水线状态变化路由到通知渠道
+type NotificationRuleRequest struct {
+	ID        uint64   `json:"id"`
+	ChannelID uint64   `json:"channel_id" binding:"required"`
+	ConfigID  uint64   `json:"config_id"` // 0 表示所有服务
+	Statuses  []string `json:"statuses"`  // 为空表示所有结束状态，仅失败可用 ["failed", "unhealthy"]
+	Enabled   *bool    `json:"enabled"`
+}
+
+// NotificationPreviewRequest 使用历史流水线渲染通知模板
+type NotificationPreviewRequest struct {
+	PipelineID uint64 `json:"pipeline_id" binding:"required"`
+	ChannelID  uint64 `json:"channel_id"` // 未提供 template 时使用该渠道的模板
+	Template   string `json:"template"`
+}
+
+// WSCommand WebSocket 客户端命令
+type WSCommand struct {
+	Action      string   `json:"action"`       // auth, subscribe, unsubscribe, cancel, replay
+	Token       string   `json:"token"`        // auth：浏览器无法在 WebSocket 上发送 Authorization 头时以此认证
+	PipelineIDs []uint64 `json:"pipeline_ids"` // subscribe/unsubscribe；首次订阅前接收所有流水线的事件
+	PipelineID  uint64   `json:"pipeline_id"`  // cancel/replay
+	Since       uint64   `json:"since"`        // replay：从该事件序号之后开始重放
+}
+
+// WSReply WebSocket 命令的执行结果
+type WSReply struct {
+	Type       string `json:"type"` // 固定为 reply
+	Action     string `json:"action"`
+	PipelineID uint64 `json:"pipeline_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// LogSearchRequest 全文搜索流水线日志
+type LogSearchRequest struct {
+	PageRequest
+	Q         string `form:"q" binding:"required"` // 空格分隔的词均需出现在同一行；双引号内为短语，词尾 * 为前缀匹配
+	ServiceID uint64 `form:"service_id"`
+	From      string `form:"from"` // RFC3339 或 2006-01-02，按流水线创建时间过滤
+	To        string `form:"to"`
+	Order     string `form:"order,default=desc" binding:"oneof=asc desc"` // asc 时最早的流水线在前
+}
+
+// LogSearchResult 日志匹配的流水线
+type LogSearchResult struct {
+	PipelineID uint64         `json:"pipeline_id"`
+	ConfigID   uint64         `json:"config_id"`
+	RepoName   string         `json:"repo_name"`
+	Status     string         `json:"status"`
+	Ref        string         `json:"ref"`
+	CommitSHA  string         `json:"commit_sha"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Matches    int            `json:"matches"` // 匹配的行数
+	Hits       []LogSearchHit `json:"hits"`    // 前若干个匹配行
+}
+
+// LogSearchHit 匹配的日志行，snippet 已做 HTML 转义，匹配词以 <mark> 标出
+type LogSearchHit struct {
+	Line    int    `json:"line"` // 从 1 开始
+	Snippet string `json:"snippet"`
+}
+
+// RetentionRunRequest 立即执行一次保留策略
+type RetentionRunRequest struct {
+	DryRun bool `json:"dry_run"` // 只统计可回收的空间，不修改数据
+}
+
+// PipelineLogDownloadRequest 下载流水线完整日志
+type PipelineLogDownloadRequest struct {
+	Timestamps bool `form:"timestamps"` // 在每行前加上 RFC3339 输出时间
+}
+
+// LogExportRequest 按日期范围导出服务的流水线日志（tar.gz）
+type LogExportRequest struct {
+	From       string `form:"from"` // RFC3339 或 2006-01-02，按流水线创建时间过滤
+	To         string `form:"to"`
+	Timestamps bool   `form:"timestamps"`
+}
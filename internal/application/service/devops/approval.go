@@ -0,0 +1,197 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	defaultApprovalTimeout = 60 * time.Minute
+	approvalReapInterval   = 30 * time.Second
+)
+
+// awaitApproval parks a new pipeline in awaiting_approval instead of running it.
+func (s *DevOpsService) awaitApproval(ctx context.Context, config *devops.RepoConfig, record *devops.PipelineRecord) error {
+	timeout := time.Duration(config.ApprovalTimeout) * time.Minute
+	if timeout <= 0 {
+		timeout = defaultApprovalTimeout
+	}
+	expiresAt := time.Now().Add(timeout)
+
+	if record.ID != 0 {
+		// A queued pipeline, which every instance tries to release.
+		claimed, err := s.repo.ClaimPipelineStatus(ctx, record.ID, record.Status, "awaiting_approval")
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return errPipelineClaimed
+		}
+	}
+	record.Status = "awaiting_approval"
+	record.Approval.ExpiresAt = &expiresAt
+	if err := s.saveRecord(ctx, record); err != nil {
+		return err
+	}
+
+	s.Broadcaster.BroadcastLog(record.ID, fmt.Sprintf("Waiting for approval until %s\n", expiresAt.Format(time.RFC3339)))
+	s.publishStatus(ctx, record.ID, record.Status)
+	return nil
+}
+
+// ApprovePipeline records the approval and starts the pipeline, or queues it
+// while a freeze window is active.
+func (s *DevOpsService) ApprovePipeline(ctx context.Context, id uint64, user, comment string) error {
+	return s.decideApproval(ctx, id, user, "approved", comment)
+}
+
+// RejectPipeline records the rejection; the pipeline never runs.
+func (s *DevOpsService) RejectPipeline(ctx context.Context, id uint64, user, comment string) error {
+	return s.decideApproval(ctx, id, user, "rejected", comment)
+}
+
+func (s *DevOpsService) decideApproval(ctx context.Context, id uint64, user, decision, comment string) error {
+	// Serialize decisions so two approvers can't start the same pipeline twice.
+	s.approvalMu.Lock()
+	defer s.approvalMu.Unlock()
+
+	record := s.repo.GetPipelineRecord(ctx, id)
+	if record == nil {
+		return ErrPipelineNotFound
+	}
+	if record.Status != "awaiting_approval" {
+		return fmt.Errorf("%w: pipeline is not awaiting approval (status: %s)", ErrPipelineStatus, record.Status)
+	}
+	if record.Approval.ExpiresAt != nil && time.Now().After(*record.Approval.ExpiresAt) {
+		s.expireApproval(ctx, record)
+		return fmt.Errorf("%w: approval request has expired", ErrPipelineStatus)
+	}
+
+	var config *devops.RepoConfig
+	if decision == "approved" {
+		if config = s.repo.GetConfig(ctx, record.ConfigID); config == nil {
+			return fmt.Errorf("config not found")
+		}
+	}
+
+	now := time.Now()
+	record.Approval.Decision = decision
+	record.Approval.DecidedBy = user
+	record.Approval.Comment = comment
+	record.Approval.DecidedAt = &now
+	if decision == "approved" {
+		record.Status = "pending"
+	} else {
+		record.Status = decision
+		record.FinishedAt = &now
+	}
+	// The mutex only serializes the decisions taken on this instance.
+	claimed, err := s.repo.ClaimPipelineStatus(ctx, record.ID, "awaiting_approval", record.Status)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return fmt.Errorf("%w: pipeline is no longer awaiting approval", ErrPipelineStatus)
+	}
+	if err := s.repo.UpdatePipelineRecord(ctx, record); err != nil {
+		return err
+	}
+
+	s.broadcastApproval(ctx, record)
+	if config != nil {
+		// Freeze windows started since the pipeline was created still apply.
+		if err := s.admitPipeline(ctx, config, record); err != nil && !errors.Is(err, errPipelineClaimed) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DevOpsService) expireApproval(ctx context.Context, record *devops.PipelineRecord) {
+	// Another instance may have decided or expired it meanwhile.
+	claimed, err := s.repo.ClaimPipelineStatus(ctx, record.ID, "awaiting_approval", "expired")
+	if err != nil {
+		log.Printf("Failed to expire pipeline %d: %v", record.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	now := time.Now()
+	record.Status = "expired"
+	record.Approval.Decision = "expired"
+	record.Approval.DecidedAt = &now
+	record.FinishedAt = &now
+	if err := s.repo.UpdatePipelineRecord(ctx, record); err != nil {
+		log.Printf("Failed to expire pipeline %d: %v", record.ID, err)
+		return
+	}
+	s.broadcastApproval(ctx, record)
+}
+
+func (s *DevOpsService) broadcastApproval(ctx context.Context, record *devops.PipelineRecord) {
+	content := fmt.Sprintf("Pipeline %s", record.Approval.Decision)
+	if record.Approval.DecidedBy != "" {
+		content += " by " + record.Approval.DecidedBy
+	}
+	if record.Approval.Comment != "" {
+		content += ": " + record.Approval.Comment
+	}
+
+	s.Broadcaster.Broadcast(LogEvent{
+		Type:       "approval",
+		PipelineID: record.ID,
+		Content:    content,
+		Status:     record.Approval.Decision,
+	})
+	s.publishStatus(ctx, record.ID, record.Status)
+}
+
+// StartApprovalReaper periodically expires pipelines whose approval window
+// has passed, including ones left over from before a restart.
+func (s *DevOpsService) StartApprovalReaper() {
+	go func() {
+		ticker := time.NewTicker(approvalReapInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.reapExpiredApprovals()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+	log.Println("Approval reaper started")
+}
+
+func (s *DevOpsService) reapExpiredApprovals() {
+	ctx := context.Background()
+	records, err := s.repo.ListPipelineRecordsByStatus(ctx, "awaiting_approval")
+	if err != nil {
+		log.Printf("Failed to list pipelines awaiting approval: %v", err)
+		return
+	}
+
+	s.approvalMu.Lock()
+	defer s.approvalMu.Unlock()
+
+	now := time.Now()
+	for i := range records {
+		if expiresAt := records[i].Approval.ExpiresAt; expiresAt == nil || !now.After(*expiresAt) {
+			continue
+		}
+		// The list was taken before the lock; a decision may have come since.
+		record := s.repo.GetPipelineRecord(ctx, records[i].ID)
+		if record == nil || record.Status != "awaiting_approval" {
+			continue
+		}
+		s.expireApproval(ctx, record)
+	}
+}
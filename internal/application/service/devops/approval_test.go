@@ -0,0 +1,82 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRerunNeedsApproval(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	repoConfig := &devops.RepoConfig{Name: "app", RequireApproval: true}
+	if err := s.repo.SaveConfig(ctx, repoConfig); err != nil {
+		t.Fatal(err)
+	}
+	newPipeline := func(status string) *devops.PipelineRecord {
+		t.Helper()
+		record := &devops.PipelineRecord{ConfigID: repoConfig.ID, RepoName: "app", Status: status, Ref: "v1.2.0", TriggerSource: "ci_cd"}
+		if err := s.repo.CreatePipelineRecord(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+		return record
+	}
+
+	for _, status := range []string{"awaiting_approval", "rejected", "expired"} {
+		if _, err := s.RerunPipeline(ctx, newPipeline(status).ID, "alice"); !errors.Is(err, ErrPipelineStatus) {
+			t.Errorf("rerun of a %s pipeline: %v, want ErrPipelineStatus", status, err)
+		}
+	}
+
+	for _, rerun := range []func(context.Context, uint64, string) (*devops.PipelineRecord, error){s.RerunPipeline, s.RollbackToPipeline} {
+		record, err := rerun(ctx, newPipeline("success").ID, "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Status != "awaiting_approval" {
+			t.Errorf("%s pipeline is %s, want awaiting_approval", record.TriggerSource, record.Status)
+		}
+	}
+}
+
+func TestApprovalDuringFreeze(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	repoConfig := &devops.RepoConfig{Name: "app", RepoURL: "https://example.com/acme/app.git", RequireApproval: true}
+	if err := s.repo.SaveConfig(ctx, repoConfig); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.HandleCICallback(ctx, dto.CICallbackRequest{RepoURL: repoConfig.RepoURL, Status: "success", Tag: "v1.2.0"}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := s.repo.ListPipelineRecordsByStatus(ctx, "awaiting_approval")
+	if err != nil || len(records) != 1 {
+		t.Fatalf("pipelines awaiting approval: %d, %v", len(records), err)
+	}
+	id := records[0].ID
+
+	// A freeze that would refuse new deploys starts before the decision.
+	start, end := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	window := &devops.FreezeWindow{Name: "release", StartAt: &start, EndAt: &end, Action: "reject", Enabled: true}
+	if err := s.repo.SaveFreezeWindow(ctx, window); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.ApprovePipeline(ctx, id, "bob", "ok"); err != nil {
+		t.Fatal(err)
+	}
+	record := s.repo.GetPipelineRecord(ctx, id)
+	if record.Status != "queued" || record.Approval.Decision != "approved" {
+		t.Errorf("approved pipeline is %s (decision %q), want queued", record.Status, record.Approval.Decision)
+	}
+
+	if err := s.ApprovePipeline(ctx, id, "bob", "again"); !errors.Is(err, ErrPipelineStatus) {
+		t.Errorf("second approval: %v, want ErrPipelineStatus", err)
+	}
+	if err := s.RejectPipeline(ctx, id+1, "bob", ""); !errors.Is(err, ErrPipelineNotFound) {
+		t.Errorf("rejecting an unknown pipeline: %v, want ErrPipelineNotFound", err)
+	}
+}
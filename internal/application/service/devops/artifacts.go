@@ -0,0 +1,221 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/infrastructure/config"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const artifactJanitorInterval = time.Hour
+
+// ErrInvalidPipelineToken is returned when an artifact upload does not carry
+// the token of its pipeline.
+var ErrInvalidPipelineToken = errors.New("invalid pipeline token")
+
+// UploadArtifact registers a file sent by a running deploy script.
+func (s *DevOpsService) UploadArtifact(ctx context.Context, pipelineID uint64, token, name string, r io.Reader) (*devops.PipelineArtifact, error) {
+	record := s.repo.GetPipelineRecord(ctx, pipelineID)
+	if record == nil {
+		return nil, ErrPipelineNotFound
+	}
+	if record.Token == "" || subtle.ConstantTimeCompare([]byte(record.Token), []byte(token)) != 1 {
+		return nil, ErrInvalidPipelineToken
+	}
+	if record.Status != "running" {
+		return nil, fmt.Errorf("artifacts can only be uploaded while the pipeline is running (status: %s)", record.Status)
+	}
+
+	return s.registerArtifact(ctx, record, name, r)
+}
+
+func (s *DevOpsService) ListArtifacts(ctx context.Context, pipelineID uint64) ([]devops.PipelineArtifact, error) {
+	if s.repo.GetPipelineRecord(ctx, pipelineID) == nil {
+		return nil, ErrPipelineNotFound
+	}
+	return s.repo.ListArtifacts(ctx, pipelineID)
+}
+
+// GetArtifactFile returns an artifact of the pipeline and its path on disk.
+func (s *DevOpsService) GetArtifactFile(ctx context.Context, pipelineID, artifactID uint64) (*devops.PipelineArtifact, string, error) {
+	a := s.repo.GetArtifact(ctx, artifactID)
+	if a == nil || a.PipelineID != pipelineID {
+		return nil, "", fmt.Errorf("artifact not found")
+	}
+	return a, s.artifacts.FullPath(a.Path), nil
+}
+
+func (s *DevOpsService) registerArtifact(ctx context.Context, record *devops.PipelineRecord, name string, r io.Reader) (*devops.PipelineArtifact, error) {
+	cfg := config.AppConfig.Artifacts
+	obj, err := s.artifacts.Save(record.ID, name, r, cfg.MaxSizeMB<<20)
+	if err != nil {
+		return nil, err
+	}
+
+	// Re-uploading a name replaces the previous file, which Save already overwrote.
+	a := s.repo.GetArtifactByName(ctx, record.ID, name)
+	if a == nil {
+		a = &devops.PipelineArtifact{
+			PipelineID: record.ID,
+			ConfigID:   record.ConfigID,
+			Name:       name,
+		}
+	}
+	a.Path = obj.Path
+	a.Size = obj.Size
+	a.SHA256 = obj.SHA256
+	a.CreatedAt = time.Now()
+	a.ExpiresAt = nil
+	if cfg.RetentionDays > 0 {
+		expiresAt := a.CreatedAt.AddDate(0, 0, cfg.RetentionDays).UTC()
+		a.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.SaveArtifact(ctx, a); err != nil {
+		return nil, err
+	}
+
+	s.Broadcaster.BroadcastLog(record.ID, fmt.Sprintf("Registered artifact %s (%d bytes, sha256 %s)\n", a.Name, a.Size, a.SHA256))
+	return a, nil
+}
+
+// stagingDir is the directory exposed to the deploy script as OPSGO_ARTIFACT_DIR.
+func (s *DevOpsService) stagingDir(recordID uint64) string {
+	return filepath.Join(config.AppConfig.Artifacts.Dir, "staging", fmt.Sprint(recordID))
+}
+
+// artifactEnv prepares the artifact directories of a pipeline and returns the
+// environment variables pointing the deploy script at them.
+func (s *DevOpsService) artifactEnv(ctx context.Context, record *devops.PipelineRecord) []string {
+	staging, err := filepath.Abs(s.stagingDir(record.ID))
+	if err == nil {
+		err = os.MkdirAll(staging, 0755)
+	}
+	if err != nil {
+		log.Printf("Failed to create artifact staging dir for pipeline %d: %v", record.ID, err)
+		return nil
+	}
+	env := []string{"OPSGO_ARTIFACT_DIR=" + staging}
+
+	// Rollbacks can redeploy the artifacts of the release they restore
+	// instead of rebuilding it.
+	if record.TriggerSource == "rollback" && record.RerunOf != 0 {
+		if previous, err := s.repo.ListArtifacts(ctx, record.RerunOf); err == nil && len(previous) > 0 {
+			if dir, err := filepath.Abs(s.artifacts.PipelineDir(record.RerunOf)); err == nil {
+				env = append(env, "OPSGO_ROLLBACK_ARTIFACT_DIR="+dir)
+			}
+		}
+	}
+	return env
+}
+
+// collectArtifacts registers every file the deploy script left in the
+// staging directory and removes it.
+func (s *DevOpsService) collectArtifacts(ctx context.Context, record *devops.PipelineRecord) {
+	staging := s.stagingDir(record.ID)
+	defer os.RemoveAll(staging)
+
+	err := filepath.WalkDir(staging, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(staging, path)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if _, err := s.registerArtifact(ctx, record, filepath.ToSlash(rel), f); err != nil {
+			s.Broadcaster.BroadcastLog(record.ID, fmt.Sprintf("Failed to register artifact %s: %v\n", rel, err))
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to collect artifacts of pipeline %d: %v", record.ID, err)
+	}
+}
+
+// StartArtifactJanitor periodically deletes expired artifacts. Artifacts of
+// the active release of each service are kept for rollbacks.
+func (s *DevOpsService) StartArtifactJanitor() {
+	go func() {
+		ticker := time.NewTicker(artifactJanitorInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if s.claimJob("artifact-janitor", artifactJanitorInterval*9/10) {
+					s.purgeExpiredArtifacts()
+				}
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+	log.Println("Artifact janitor started")
+}
+
+func (s *DevOpsService) purgeExpiredArtifacts() {
+	ctx := context.Background()
+	expired, err := s.repo.ListExpiredArtifacts(ctx, time.Now())
+	if err != nil {
+		log.Printf("Failed to list expired artifacts: %v", err)
+		return
+	}
+
+	current := make(map[uint64]uint64) // config ID -> pipeline ID of the deployed release
+	for _, a := range expired {
+		deployed, ok := current[a.ConfigID]
+		if !ok {
+			deployed = s.deployedPipelineID(ctx, a.ConfigID)
+			current[a.ConfigID] = deployed
+		}
+		if a.PipelineID == deployed {
+			continue
+		}
+
+		if err := s.artifacts.Delete(a.Path); err != nil {
+			log.Printf("Failed to delete artifact file %s: %v", a.Path, err)
+			continue
+		}
+		if err := s.repo.DeleteArtifact(ctx, a.ID); err != nil {
+			log.Printf("Failed to delete artifact %d: %v", a.ID, err)
+		}
+	}
+}
+
+// deployedPipelineID returns the pipeline of the active release, as
+// rollbackTarget finds it, or 0 if the service has none.
+func (s *DevOpsService) deployedPipelineID(ctx context.Context, configID uint64) uint64 {
+	if release := s.repo.GetActiveRelease(ctx, configID); release != nil {
+		return release.PipelineID
+	}
+	// database/sql rejects uint64 values with the high bit set.
+	if record := s.repo.GetLastSuccessfulPipeline(ctx, configID, math.MaxInt64); record != nil {
+		return record.ID
+	}
+	return 0
+}
+
+func newPipelineToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
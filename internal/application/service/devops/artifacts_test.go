@@ -0,0 +1,70 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPurgeExpiredArtifacts(t *testing.T) {
+	tests := []struct {
+		name     string
+		active   int // index of the pipeline of the active release, -1 for none
+		statuses []string
+		kept     []bool
+	}{
+		{
+			name:     "active release",
+			active:   1,
+			statuses: []string{"success", "success", "success"},
+			kept:     []bool{false, true, false},
+		},
+		{
+			name:     "latest successful pipeline without releases",
+			active:   -1,
+			statuses: []string{"success", "success", "failed"},
+			kept:     []bool{false, true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			ctx := context.Background()
+			expired := time.Now().Add(-time.Hour).UTC()
+
+			var artifacts []*devops.PipelineArtifact
+			for i, status := range tt.statuses {
+				record := &devops.PipelineRecord{ConfigID: 1, Status: status, CreatedAt: time.Now()}
+				if err := s.repo.CreatePipelineRecord(ctx, record); err != nil {
+					t.Fatal(err)
+				}
+				if i == tt.active {
+					if err := s.repo.ActivateRelease(ctx, &devops.Release{ConfigID: 1, PipelineID: record.ID}); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				obj, err := s.artifacts.Save(record.ID, "app.tar.gz", strings.NewReader("build"), 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				a := &devops.PipelineArtifact{PipelineID: record.ID, ConfigID: 1, Name: "app.tar.gz", Path: obj.Path, ExpiresAt: &expired}
+				if err := s.repo.SaveArtifact(ctx, a); err != nil {
+					t.Fatal(err)
+				}
+				artifacts = append(artifacts, a)
+			}
+
+			s.purgeExpiredArtifacts()
+
+			for i, a := range artifacts {
+				if kept := s.repo.GetArtifact(ctx, a.ID) != nil; kept != tt.kept[i] {
+					t.Errorf("artifact of pipeline %d kept = %v, want %v", a.PipelineID, kept, tt.kept[i])
+				}
+			}
+		})
+	}
+}
@@ -1,31 +1,70 @@
 package devops
 
 import (
+	"OpsGo/internal/infrastructure/ansi"
+	"OpsGo/internal/infrastructure/config"
+	"OpsGo/internal/infrastructure/redis"
+	"expvar"
 	"fmt"
+	"log"
 	"sync"
 )
 
+const (
+	clientBufferSize  = 100
+	clientBacklogSize = 5000 // events buffered per client before it misses events
+	historySize       = 5000 // recent events kept per pipeline for replays
+	historyPipelines  = 100  // pipelines with kept events, oldest are forgotten first
+)
+
+// broadcasterMetrics is served on /debug/vars: events published, events
+// dropped for slow clients, gap events sent and connected clients.
+var broadcasterMetrics = expvar.NewMap("devops_broadcaster")
+
 type LogEvent struct {
-	Type       string `json:"type"` // log, status
-	PipelineID uint64 `json:"pipeline_id"`
-	Content    string `json:"content,omitempty"`
-	Status     string `json:"status,omitempty"`
+	Type       string      `json:"type"` // log, status, approval, gap
+	PipelineID uint64      `json:"pipeline_id"`
+	Seq        uint64      `json:"seq"` // per pipeline, starting at 1; for gaps the first missed event
+	Content    string      `json:"content,omitempty"`
+	Spans      []ansi.Span `json:"spans,omitempty"` // log: Content with its colors, see pipeline_log.ansi
+	Status     string      `json:"status,omitempty"`
+	Missed     int         `json:"missed,omitempty"` // gap: events from Seq on that were not delivered
+	Resync     string      `json:"resync,omitempty"` // gap: where to reload the pipeline from
+}
+
+// Broadcaster fans pipeline events out to the SSE and WebSocket clients of
+// every OpsGo instance. Events of a pipeline are delivered in the order they
+// were sent. Publishing never blocks; clients too slow to keep up get a gap
+// event instead of the events they missed.
+type Broadcaster interface {
+	Register() chan LogEvent
+	Unregister(client chan LogEvent)
+	Broadcast(event LogEvent)
+	BroadcastLog(pipelineID uint64, content string)
+	BroadcastStatus(pipelineID uint64, status string)
+	// Replay returns the recent events of a pipeline after seq, preceded by a
+	// gap event if some of them are no longer kept.
+	Replay(pipelineID, seq uint64) []LogEvent
 }
 
+// LogBroadcaster is the in-memory Broadcaster, for single instance deployments.
 type LogBroadcaster struct {
-	clients    map[chan LogEvent]bool
+	clients    map[chan LogEvent]*subscriber
 	register   chan chan LogEvent
 	unregister chan chan LogEvent
-	broadcast  chan LogEvent
-	mu         sync.RWMutex
+	queue      *eventQueue
+	seq        sequencer
+	history    *eventHistory
 }
 
 func NewLogBroadcaster() *LogBroadcaster {
 	lb := &LogBroadcaster{
-		clients:    make(map[chan LogEvent]bool),
+		clients:    make(map[chan LogEvent]*subscriber),
 		register:   make(chan chan LogEvent),
 		unregister: make(chan chan LogEvent),
-		broadcast:  make(chan LogEvent),
+		queue:      newEventQueue(),
+		seq:        sequencer{next: make(map[uint64]uint64)},
+		history:    newEventHistory(),
 	}
 	go lb.run()
 	return lb
@@ -35,34 +74,129 @@ func (lb *LogBroadcaster) run() {
 	for {
 		select {
 		case client := <-lb.register:
-			lb.mu.Lock()
-			lb.clients[client] = true
-			lb.mu.Unlock()
+			sub := newSubscriber(client)
+			lb.clients[client] = sub
+			go sub.write()
+			broadcasterMetrics.Add("clients", 1)
 			fmt.Println("New SSE client registered")
 		case client := <-lb.unregister:
-			lb.mu.Lock()
-			if _, ok := lb.clients[client]; ok {
+			if sub, ok := lb.clients[client]; ok {
 				delete(lb.clients, client)
-				close(client)
+				close(sub.done)
+				broadcasterMetrics.Add("clients", -1)
 			}
-			lb.mu.Unlock()
 			fmt.Println("SSE client unregistered")
-		case event := <-lb.broadcast:
-			lb.mu.RLock()
-			for client := range lb.clients {
+		case <-lb.queue.ready:
+			for _, event := range lb.queue.drain() {
+				lb.history.add(event)
+				for _, sub := range lb.clients {
+					sub.enqueue(event)
+				}
+			}
+		}
+	}
+}
+
+// subscriber buffers events for one client, so bursts do not cost slow
+// readers anything. A client whose backlog is full misses events until it
+// caught up, and is then told about them with one gap event per pipeline.
+type subscriber struct {
+	ch      chan LogEvent
+	done    chan struct{}
+	ready   chan struct{}
+	mu      sync.Mutex
+	backlog []LogEvent
+	gaps    []LogEvent
+}
+
+func newSubscriber(ch chan LogEvent) *subscriber {
+	return &subscriber{
+		ch:    ch,
+		done:  make(chan struct{}),
+		ready: make(chan struct{}, 1),
+	}
+}
+
+func (sub *subscriber) enqueue(event LogEvent) {
+	sub.mu.Lock()
+	if len(sub.gaps) == 0 && len(sub.backlog) < clientBacklogSize {
+		sub.backlog = append(sub.backlog, event)
+	} else {
+		sub.miss(event)
+	}
+	sub.mu.Unlock()
+
+	select {
+	case sub.ready <- struct{}{}:
+	default:
+	}
+}
+
+// miss folds an undelivered event into the gap of its pipeline. Once a gap is
+// open every later event is missed too, so a gap covers consecutive events.
+func (sub *subscriber) miss(event LogEvent) {
+	broadcasterMetrics.Add("dropped", 1)
+	for i := range sub.gaps {
+		if sub.gaps[i].PipelineID == event.PipelineID {
+			sub.gaps[i].Missed++
+			return
+		}
+	}
+	sub.gaps = append(sub.gaps, newGap(event.PipelineID, event.Seq, 1))
+}
+
+func newGap(pipelineID, seq uint64, missed int) LogEvent {
+	return LogEvent{
+		Type:       "gap",
+		PipelineID: pipelineID,
+		Seq:        seq,
+		Missed:     missed,
+		Resync:     fmt.Sprintf("%s/pipelines/%d", apiPrefix, pipelineID),
+	}
+}
+
+// write forwards the backlog, then pending gaps, to the client channel. It
+// owns the channel and closes it once the client is unregistered.
+func (sub *subscriber) write() {
+	defer close(sub.ch)
+
+	for {
+		select {
+		case <-sub.ready:
+		case <-sub.done:
+			return
+		}
+
+		for {
+			sub.mu.Lock()
+			events := sub.backlog
+			sub.backlog = nil
+			gaps := false
+			if len(events) == 0 {
+				events, sub.gaps = sub.gaps, nil
+				gaps = true
+			}
+			sub.mu.Unlock()
+			if len(events) == 0 {
+				break
+			}
+
+			for _, event := range events {
 				select {
-				case client <- event:
-				default:
-					// If client buffer is full, we might want to drop or handle it
+				case sub.ch <- event:
+				case <-sub.done:
+					return
 				}
 			}
-			lb.mu.RUnlock()
+			if gaps {
+				broadcasterMetrics.Add("gaps", int64(len(events)))
+			}
 		}
 	}
 }
 
 func (lb *LogBroadcaster) Register() chan LogEvent {
-	client := make(chan LogEvent, 100)
+	client := make(chan LogEvent, clientBufferSize)
 	lb.register <- client
 	return client
 }
@@ -71,18 +205,144 @@ func (lb *LogBroadcaster) Unregister(client chan LogEvent) {
 	lb.unregister <- client
 }
 
+func (lb *LogBroadcaster) Broadcast(event LogEvent) {
+	lb.seq.stamp(&event)
+	lb.relay(event)
+}
+
+// relay fans out an event that already has its sequence number.
+func (lb *LogBroadcaster) relay(event LogEvent) {
+	broadcasterMetrics.Add("published", 1)
+	lb.queue.push(event)
+}
+
+func (lb *LogBroadcaster) Replay(pipelineID, seq uint64) []LogEvent {
+	return lb.history.since(pipelineID, seq)
+}
+
 func (lb *LogBroadcaster) BroadcastLog(pipelineID uint64, content string) {
-	lb.broadcast <- LogEvent{
+	lb.Broadcast(LogEvent{
 		Type:       "log",
 		PipelineID: pipelineID,
 		Content:    content,
-	}
+	})
 }
 
 func (lb *LogBroadcaster) BroadcastStatus(pipelineID uint64, status string) {
-	lb.broadcast <- LogEvent{
+	lb.Broadcast(LogEvent{
 		Type:       "status",
 		PipelineID: pipelineID,
 		Status:     status,
+	})
+}
+
+// eventQueue is an unbounded FIFO between publishers and a single consumer,
+// so publishers never wait for the consumer.
+type eventQueue struct {
+	mu     sync.Mutex
+	events []LogEvent
+	ready  chan struct{}
+}
+
+func newEventQueue() *eventQueue {
+	return &eventQueue{ready: make(chan struct{}, 1)}
+}
+
+func (q *eventQueue) push(event LogEvent) {
+	q.mu.Lock()
+	q.events = append(q.events, event)
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default: // the consumer is already signaled
+	}
+}
+
+func (q *eventQueue) drain() []LogEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	events := q.events
+	q.events = nil
+	return events
+}
+
+// sequencer numbers the events of each pipeline.
+type sequencer struct {
+	mu   sync.Mutex
+	next map[uint64]uint64
+}
+
+func (s *sequencer) stamp(event *LogEvent) {
+	s.mu.Lock()
+	s.next[event.PipelineID]++
+	event.Seq = s.next[event.PipelineID]
+	s.mu.Unlock()
+}
+
+// observe continues numbering after an event numbered elsewhere.
+func (s *sequencer) observe(event *LogEvent) {
+	s.mu.Lock()
+	s.next[event.PipelineID] = max(s.next[event.PipelineID], event.Seq)
+	s.mu.Unlock()
+}
+
+// eventHistory keeps the latest events of recent pipelines, so reconnecting
+// clients can catch up on what they missed.
+type eventHistory struct {
+	mu     sync.Mutex
+	events map[uint64][]LogEvent
+	order  []uint64 // pipelines in the order their first event was kept
+}
+
+func newEventHistory() *eventHistory {
+	return &eventHistory{events: make(map[uint64][]LogEvent)}
+}
+
+func (h *eventHistory) add(event LogEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	events, ok := h.events[event.PipelineID]
+	if !ok {
+		h.order = append(h.order, event.PipelineID)
+		if len(h.order) > historyPipelines {
+			delete(h.events, h.order[0])
+			h.order = h.order[1:]
+		}
+	}
+	if len(events) >= historySize {
+		// Drop the oldest tenth at once rather than copying on every event.
+		events = append(events[:0:0], events[historySize/10:]...)
+	}
+	h.events[event.PipelineID] = append(events, event)
+}
+
+func (h *eventHistory) since(pipelineID, seq uint64) []LogEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	events := h.events[pipelineID]
+	var replay []LogEvent
+	if len(events) > 0 && events[0].Seq > seq+1 {
+		replay = append(replay, newGap(pipelineID, seq+1, int(events[0].Seq-seq-1)))
+	}
+	for _, event := range events {
+		if event.Seq > seq {
+			replay = append(replay, event)
+		}
+	}
+	return replay
+}
+
+// newBroadcaster selects the broadcaster backend configured for this instance.
+func newBroadcaster() Broadcaster {
+	cfg := config.AppConfig.Broadcaster
+	if cfg.Backend == "redis" {
+		if redis.Client != nil {
+			return NewRedisBroadcaster(redis.Client, cfg.Channel)
+		}
+		log.Println("Warning: Redis is unavailable, falling back to the in-memory broadcaster")
 	}
+	return NewLogBroadcaster()
 }
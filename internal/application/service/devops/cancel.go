@@ -0,0 +1,103 @@
+package devops
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// activeRun is a pipeline executing on this instance.
+type activeRun struct {
+	cancel     context.CancelFunc
+	canceledBy string
+}
+
+// trackRun returns the context the pipeline's scripts run under; canceling the
+// pipeline cancels it. release must be called once the pipeline finished.
+func (s *DevOpsService) trackRun(recordID uint64) (ctx context.Context, release func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s.runsMu.Lock()
+	s.runs[recordID] = &activeRun{cancel: cancel}
+	s.runsMu.Unlock()
+
+	return ctx, func() {
+		s.runsMu.Lock()
+		delete(s.runs, recordID)
+		s.runsMu.Unlock()
+		cancel()
+	}
+}
+
+// canceledBy reports who canceled a running pipeline.
+func (s *DevOpsService) canceledBy(recordID uint64) string {
+	s.runsMu.Lock()
+	defer s.runsMu.Unlock()
+	if run := s.runs[recordID]; run != nil {
+		return run.canceledBy
+	}
+	return ""
+}
+
+// CancelPipeline stops a running pipeline, or drops one that is still waiting
+// for a freeze window or an approval.
+func (s *DevOpsService) CancelPipeline(ctx context.Context, id uint64, user string) error {
+	record := s.repo.GetPipelineRecord(ctx, id)
+	if record == nil {
+		return ErrPipelineNotFound
+	}
+
+	switch record.Status {
+	case "queued", "awaiting_approval":
+		// Hold the approval lock so an approver can't start it meanwhile.
+		s.approvalMu.Lock()
+		defer s.approvalMu.Unlock()
+
+		if record = s.repo.GetPipelineRecord(ctx, id); record == nil {
+			return ErrPipelineNotFound
+		}
+		if record.Status != "queued" && record.Status != "awaiting_approval" {
+			return fmt.Errorf("%w: pipeline cannot be canceled (status: %s)", ErrPipelineStatus, record.Status)
+		}
+		// The lock doesn't cover the freeze queue nor other instances.
+		claimed, err := s.repo.ClaimPipelineStatus(ctx, id, record.Status, "canceled")
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return fmt.Errorf("%w: pipeline was started or canceled meanwhile", ErrPipelineStatus)
+		}
+
+		now := time.Now()
+		record.Status = "canceled"
+		record.FinishedAt = &now
+		if err := s.repo.UpdatePipelineRecord(ctx, record); err != nil {
+			return err
+		}
+		s.Broadcaster.BroadcastLog(id, canceledMessage(user))
+		s.publishStatus(ctx, id, record.Status)
+		return nil
+
+	case "pending", "running":
+		s.runsMu.Lock()
+		defer s.runsMu.Unlock()
+
+		run := s.runs[id]
+		if run == nil {
+			return fmt.Errorf("pipeline is not running on this instance")
+		}
+		run.canceledBy = user
+		run.cancel()
+		return nil
+
+	default:
+		return fmt.Errorf("%w: pipeline cannot be canceled (status: %s)", ErrPipelineStatus, record.Status)
+	}
+}
+
+func canceledMessage(user string) string {
+	if user == "" {
+		return "\nPipeline canceled\n"
+	}
+	return fmt.Sprintf("\nPipeline canceled by %s\n", user)
+}
@@ -0,0 +1,55 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/domain/repository"
+	"context"
+	"errors"
+	"testing"
+)
+
+// racingRepo starts queued pipelines right before a status claim, as the
+// freeze queue of another instance would.
+type racingRepo struct {
+	repository.DevOpsRepository
+}
+
+func (r racingRepo) ClaimPipelineStatus(ctx context.Context, id uint64, from, to string) (bool, error) {
+	if _, err := r.DevOpsRepository.ClaimPipelineStatus(ctx, id, "queued", "running"); err != nil {
+		return false, err
+	}
+	return r.DevOpsRepository.ClaimPipelineStatus(ctx, id, from, to)
+}
+
+func TestCancelQueuedPipeline(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	newQueued := func() uint64 {
+		t.Helper()
+		record := &devops.PipelineRecord{RepoName: "app", Status: "queued"}
+		if err := s.repo.CreatePipelineRecord(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+		return record.ID
+	}
+
+	id := newQueued()
+	if err := s.CancelPipeline(ctx, id, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if record := s.repo.GetPipelineRecord(ctx, id); record.Status != "canceled" || record.FinishedAt == nil {
+		t.Errorf("canceled pipeline is %s, finished at %v", record.Status, record.FinishedAt)
+	}
+	if err := s.CancelPipeline(ctx, id, "alice"); !errors.Is(err, ErrPipelineStatus) {
+		t.Errorf("second cancel: %v, want ErrPipelineStatus", err)
+	}
+
+	id = newQueued()
+	s.repo = racingRepo{s.repo}
+	if err := s.CancelPipeline(ctx, id, "alice"); !errors.Is(err, ErrPipelineStatus) {
+		t.Errorf("cancel of a pipeline started meanwhile: %v, want ErrPipelineStatus", err)
+	}
+	if record := s.repo.GetPipelineRecord(ctx, id); record.Status != "running" {
+		t.Errorf("pipeline started meanwhile is %s, want running", record.Status)
+	}
+}
@@ -0,0 +1,143 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/infrastructure/config"
+	"OpsGo/internal/infrastructure/gitrepo"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// GetChangelog returns the commits a pipeline deployed since the previous release.
+func (s *DevOpsService) GetChangelog(ctx context.Context, pipelineID uint64) (*dto.ChangelogResponse, error) {
+	record := s.repo.GetPipelineRecord(ctx, pipelineID)
+	if record == nil {
+		return nil, ErrPipelineNotFound
+	}
+
+	commits := record.Changelog
+	if commits == nil {
+		commits = []devops.ChangelogCommit{}
+	}
+	return &dto.ChangelogResponse{
+		PipelineID: record.ID,
+		From:       record.ChangelogFrom,
+		To:         record.CommitSHA,
+		Commits:    commits,
+	}, nil
+}
+
+// resolveCommit syncs the mirror of the service repository and, when the
+// pipeline doesn't name a commit, resolves its ref, so the SHA is known while
+// the pipeline runs and the changelog can be built from the mirror once it
+// finished. Failures are reported in the returned log line and never fail the
+// pipeline.
+func (s *DevOpsService) resolveCommit(ctx context.Context, record *devops.PipelineRecord, repoConfig *devops.RepoConfig) string {
+	if repoConfig.RepoURL == "" {
+		return ""
+	}
+
+	gitCfg := config.AppConfig.Git
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(gitCfg.Timeout)*time.Second)
+	defer cancel()
+
+	mirror := serviceMirror(repoConfig)
+
+	// Pipelines of the same service may start together; git cannot fetch
+	// into one repository concurrently. Other services' mirrors aren't
+	// locked, so a slow remote only holds up the deploys of its service.
+	mu := s.mirrorLock(repoConfig.ID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := mirror.Sync(ctx); err != nil {
+		return fmt.Sprintf("Changelog unavailable: %v\n", err)
+	}
+	if record.CommitSHA != "" {
+		return ""
+	}
+
+	// Manual and scheduled deploys without a ref deploy the default branch.
+	ref := record.Ref
+	if ref == "" || ref == "manual" || ref == "latest" {
+		ref = "HEAD"
+	}
+	sha, err := mirror.Resolve(ctx, ref)
+	if err != nil {
+		return fmt.Sprintf("Changelog unavailable: %v\n", err)
+	}
+
+	record.CommitSHA = sha
+	stored := s.repo.GetPipelineRecord(ctx, record.ID)
+	if stored == nil {
+		return ""
+	}
+	stored.CommitSHA = sha
+	s.repo.UpdatePipelineRecord(ctx, stored)
+	return fmt.Sprintf("Deploying commit %.7s\n", sha)
+}
+
+// attachChangelog stores the commits between the previous release and the
+// one a successful pipeline deployed. It only reads the mirror resolveCommit
+// synced, so a slow remote never holds up the end of the pipeline.
+func (s *DevOpsService) attachChangelog(ctx context.Context, record *devops.PipelineRecord, repoConfig *devops.RepoConfig, previous *devops.Release) string {
+	if repoConfig.RepoURL == "" || record.CommitSHA == "" {
+		return ""
+	}
+
+	var from string
+	if previous != nil {
+		from = previous.CommitSHA
+	}
+	if from == "" {
+		// First release of the service: there is nothing to compare against.
+		s.saveChangelog(ctx, record, "", nil)
+		return ""
+	}
+
+	gitCfg := config.AppConfig.Git
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(gitCfg.Timeout)*time.Second)
+	defer cancel()
+
+	commits, err := serviceMirror(repoConfig).Log(ctx, from, record.CommitSHA, gitCfg.MaxCommits)
+	if err != nil {
+		return fmt.Sprintf("Changelog unavailable: %v\n", err)
+	}
+
+	changelog := make([]devops.ChangelogCommit, 0, len(commits))
+	for _, c := range commits {
+		changelog = append(changelog, devops.ChangelogCommit{
+			SHA:     c.SHA,
+			Author:  c.Author,
+			Email:   c.Email,
+			Date:    c.Date,
+			Subject: c.Subject,
+		})
+	}
+	s.saveChangelog(ctx, record, from, changelog)
+	return fmt.Sprintf("Changelog: %d commit(s) since %.7s\n", len(changelog), from)
+}
+
+func (s *DevOpsService) saveChangelog(ctx context.Context, record *devops.PipelineRecord, from string, changelog []devops.ChangelogCommit) {
+	stored := s.repo.GetPipelineRecord(ctx, record.ID)
+	if stored == nil {
+		return
+	}
+	stored.ChangelogFrom = from
+	stored.Changelog = changelog
+	s.repo.UpdatePipelineRecord(ctx, stored)
+}
+
+// mirrorLock returns the lock serializing the fetches into a service's mirror.
+func (s *DevOpsService) mirrorLock(configID uint64) *sync.Mutex {
+	mu, _ := s.mirrorLocks.LoadOrStore(configID, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
+func serviceMirror(repoConfig *devops.RepoConfig) *gitrepo.Mirror {
+	return gitrepo.NewMirror(filepath.Join(config.AppConfig.Git.WorkspaceDir, fmt.Sprintf("%d.git", repoConfig.ID)), repoConfig.RepoURL)
+}
@@ -0,0 +1,171 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/infrastructure/config"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// gitCommit commits a change to the repository at dir and returns its SHA.
+func gitCommit(t *testing.T, dir, subject string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "VERSION"), []byte(subject), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"add", "VERSION"},
+		{"-c", "user.name=Dev", "-c", "user.email=dev@example.com", "commit", "--quiet", "-m", subject},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v: %s", args[0], err, out)
+		}
+	}
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestScheduledPipelineChangelog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	s := newTestService(t)
+	ctx := context.Background()
+	config.AppConfig.Git.WorkspaceDir = t.TempDir()
+	config.AppConfig.Git.Timeout = 30
+	config.AppConfig.Git.MaxCommits = 10
+
+	upstream := t.TempDir()
+	if out, err := exec.Command("git", "init", "--quiet", upstream).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	first := gitCommit(t, upstream, "v1")
+
+	script := filepath.Join(t.TempDir(), "deploy.sh")
+	if err := os.WriteFile(script, []byte("exit 0\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	repoConfig := &devops.RepoConfig{Name: "app", RepoURL: upstream, DeployScript: script}
+	if err := s.repo.SaveConfig(ctx, repoConfig); err != nil {
+		t.Fatal(err)
+	}
+	schedule := &devops.DeploySchedule{ConfigID: repoConfig.ID}
+	var record *devops.PipelineRecord
+
+	// Listeners, such as notifications, are told once the changelog is saved.
+	finished := make(chan *devops.PipelineRecord, 10)
+	s.OnStatusChange(func(record *devops.PipelineRecord) {
+		if slices.Contains(finalStatuses, record.Status) {
+			finished <- record
+		}
+	})
+	wait := func() *devops.PipelineRecord {
+		t.Helper()
+		select {
+		case record := <-finished:
+			return record
+		case <-time.After(10 * time.Second):
+			t.Fatal("pipeline did not finish")
+			return nil
+		}
+	}
+	// The release is recorded after listeners were told.
+	waitRelease := func() *devops.Release {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+			if release := s.repo.GetActiveRelease(ctx, repoConfig.ID); release != nil && release.PipelineID == record.ID {
+				return release
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("no release of pipeline %d", record.ID)
+			}
+		}
+	}
+
+	record, err := s.runSchedule(ctx, schedule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := wait()
+	if got.ID != record.ID || got.Status != "success" || got.CommitSHA != first {
+		t.Fatalf("first pipeline: status %s, commit %q, want %q", got.Status, got.CommitSHA, first)
+	}
+	waitRelease()
+
+	gitCommit(t, upstream, "v2")
+	third := gitCommit(t, upstream, "v3")
+
+	record, err = s.runSchedule(ctx, schedule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = wait()
+	if got.CommitSHA != third || got.ChangelogFrom != first {
+		t.Errorf("second pipeline: commit %q from %q, want %q from %q", got.CommitSHA, got.ChangelogFrom, third, first)
+	}
+	var subjects []string
+	for _, c := range got.Changelog {
+		subjects = append(subjects, c.Subject)
+	}
+	if want := []string{"v3", "v2"}; !slices.Equal(subjects, want) {
+		t.Errorf("changelog %q, want %q", subjects, want)
+	}
+	if !strings.Contains(got.Log, "Changelog: 2 commit(s)") {
+		t.Errorf("log lacks the changelog line:\n%s", got.Log)
+	}
+	if release := waitRelease(); release.CommitSHA != third {
+		t.Errorf("active release has commit %q, want %q", release.CommitSHA, third)
+	}
+}
+
+func TestResolveCommitLocksOnlyItsMirror(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	s := newTestService(t)
+	ctx := context.Background()
+	config.AppConfig.Git.WorkspaceDir = t.TempDir()
+	config.AppConfig.Git.Timeout = 30
+
+	upstream := t.TempDir()
+	if out, err := exec.Command("git", "init", "--quiet", upstream).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	head := gitCommit(t, upstream, "v1")
+
+	slow := &devops.RepoConfig{Name: "slow", RepoURL: upstream}
+	app := &devops.RepoConfig{Name: "app", RepoURL: upstream}
+	for _, c := range []*devops.RepoConfig{slow, app} {
+		if err := s.repo.SaveConfig(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record := &devops.PipelineRecord{ConfigID: app.ID, RepoName: "app", Status: "pending", Ref: "latest"}
+	if err := s.repo.CreatePipelineRecord(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fetch of the other service that never ends.
+	s.mirrorLock(slow.ID).Lock()
+	defer s.mirrorLock(slow.ID).Unlock()
+
+	done := make(chan string, 1)
+	go func() { done <- s.resolveCommit(ctx, record, app) }()
+	select {
+	case msg := <-done:
+		if record.CommitSHA != head {
+			t.Errorf("resolved %q (%q), want %s", record.CommitSHA, msg, head)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("resolveCommit waited for the mirror of another service")
+	}
+}
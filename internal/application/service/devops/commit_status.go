@@ -0,0 +1,169 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/domain/repository"
+	"OpsGo/internal/infrastructure/config"
+	"OpsGo/internal/infrastructure/forge"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultForgeContext = "deployment"
+	forgeReportTimeout  = 30 * time.Second
+)
+
+// CommitStatusService reports pipeline results as commit statuses on the
+// Git forge configured for the service.
+type CommitStatusService struct {
+	repo repository.DevOpsRepository
+
+	mu      sync.Mutex
+	pending map[uint64][]*devops.PipelineRecord // per pipeline, in status order
+}
+
+func NewCommitStatusService(repo repository.DevOpsRepository) *CommitStatusService {
+	return &CommitStatusService{
+		repo:    repo,
+		pending: make(map[uint64][]*devops.PipelineRecord),
+	}
+}
+
+func (s *CommitStatusService) ListReports(ctx context.Context, pipelineID uint64) ([]devops.CommitStatusReport, error) {
+	if s.repo.GetPipelineRecord(ctx, pipelineID) == nil {
+		return nil, ErrPipelineNotFound
+	}
+	return s.repo.ListCommitStatusReports(ctx, pipelineID)
+}
+
+// Report is a StatusListener. Statuses of one pipeline are posted in order,
+// so a slow "pending" never overwrites the final result on the forge.
+func (s *CommitStatusService) Report(record *devops.PipelineRecord) {
+	if forgeState(record.Status) == "" {
+		return
+	}
+
+	s.mu.Lock()
+	queue := s.pending[record.ID]
+	s.pending[record.ID] = append(queue, record)
+	s.mu.Unlock()
+
+	if len(queue) == 0 {
+		go s.drain(record.ID)
+	}
+}
+
+func (s *CommitStatusService) drain(pipelineID uint64) {
+	for {
+		s.mu.Lock()
+		queue := s.pending[pipelineID]
+		if len(queue) == 0 {
+			delete(s.pending, pipelineID)
+			s.mu.Unlock()
+			return
+		}
+		s.mu.Unlock()
+
+		s.report(queue[0])
+
+		s.mu.Lock()
+		s.pending[pipelineID] = s.pending[pipelineID][1:]
+		s.mu.Unlock()
+	}
+}
+
+func (s *CommitStatusService) report(record *devops.PipelineRecord) {
+	ctx := context.Background()
+	repoConfig := s.repo.GetConfig(ctx, record.ConfigID)
+	if repoConfig == nil || repoConfig.Forge.Type == "" || record.CommitSHA == "" {
+		return
+	}
+
+	report := func(attempt, code int, err error) {
+		r := &devops.CommitStatusReport{
+			PipelineID: record.ID,
+			ConfigID:   record.ConfigID,
+			Forge:      repoConfig.Forge.Type,
+			CommitSHA:  record.CommitSHA,
+			State:      forgeState(record.Status),
+			Attempt:    attempt,
+			StatusCode: code,
+			CreatedAt:  time.Now(),
+		}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		if err := s.repo.CreateCommitStatusReport(ctx, r); err != nil {
+			log.Printf("Failed to save commit status report of pipeline %d: %v", record.ID, err)
+		}
+	}
+
+	client, err := forge.NewClient(repoConfig.Forge.Type, repoConfig.Forge.APIURL, repoConfig.Forge.Token)
+	if err != nil {
+		report(1, 0, err)
+		return
+	}
+	owner, repo, err := forge.ParseRepo(repoConfig.RepoURL)
+	if err != nil {
+		report(1, 0, err)
+		return
+	}
+	status := commitStatus(record, repoConfig.Forge.Context)
+
+	// Retries share the delivery settings of notifications.
+	cfg := config.AppConfig.Notify
+	backoff := time.Duration(cfg.Backoff) * time.Second
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(ctx, forgeReportTimeout)
+		code, err := client.SetCommitStatus(ctx, owner, repo, record.CommitSHA, status)
+		cancel()
+		report(attempt, code, err)
+
+		// Client errors other than rate limiting will not go away by retrying.
+		retryable := code == 0 || code == 429 || code >= 500
+		if err == nil || !retryable || attempt >= cfg.MaxAttempts {
+			if err != nil {
+				log.Printf("Failed to report status of pipeline %d to %s: %v", record.ID, repoConfig.Forge.Type, err)
+			}
+			return
+		}
+		sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// forgeState maps a pipeline status to a commit status state; statuses
+// without a mapping are not reported.
+func forgeState(status string) string {
+	switch status {
+	case "pending", "running":
+		return forge.StatePending
+	case "success":
+		return forge.StateSuccess
+	case "failed", "unhealthy":
+		return forge.StateFailure
+	case "canceled", "rejected", "expired":
+		return forge.StateError
+	}
+	return ""
+}
+
+func commitStatus(record *devops.PipelineRecord, name string) forge.Status {
+	if name == "" {
+		name = defaultForgeContext
+	}
+	status := forge.Status{
+		State:       forgeState(record.Status),
+		Description: fmt.Sprintf("OpsGo pipeline #%d: %s", record.ID, record.Status),
+		Context:     name,
+	}
+	if base := strings.TrimSuffix(config.AppConfig.Notify.BaseURL, "/"); base != "" {
+		status.TargetURL = fmt.Sprintf("%s%s/pipelines/%d", base, apiPrefix, record.ID)
+	}
+	return status
+}
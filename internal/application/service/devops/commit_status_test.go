@@ -0,0 +1,128 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/infrastructure/config"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCommitStatusReportRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []int // forge response per attempt, 0 closes the connection
+		wantCodes []int // recorded per attempt
+		wantWaits []time.Duration
+	}{
+		{name: "created", responses: []int{201}, wantCodes: []int{201}},
+		{name: "server error then created", responses: []int{502, 201}, wantCodes: []int{502, 201}, wantWaits: []time.Duration{5 * time.Second}},
+		{name: "rate limited", responses: []int{429, 429, 429}, wantCodes: []int{429, 429, 429}, wantWaits: []time.Duration{5 * time.Second, 10 * time.Second}},
+		{name: "unreachable", responses: []int{0, 201}, wantCodes: []int{0, 201}, wantWaits: []time.Duration{5 * time.Second}},
+		{name: "unauthorized", responses: []int{401}, wantCodes: []int{401}},
+		{name: "unknown commit", responses: []int{422}, wantCodes: []int{422}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			ctx := context.Background()
+			config.AppConfig.Notify.MaxAttempts = 3
+			config.AppConfig.Notify.Backoff = 5
+			waits := recordSleeps(t)
+
+			var auth []string
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auth = append(auth, r.Header.Get("Authorization"))
+				code := tt.responses[min(calls, len(tt.responses)-1)]
+				calls++
+				if code == 0 {
+					if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+						conn.Close()
+					}
+					return
+				}
+				w.WriteHeader(code)
+			}))
+			defer srv.Close()
+
+			repoConfig := &devops.RepoConfig{
+				Name:    "app",
+				RepoURL: "git@gitea.example.com:acme/app.git",
+				Forge:   devops.ForgeSettings{Type: "gitea", APIURL: srv.URL, Token: "tea123"},
+			}
+			if err := s.repo.SaveConfig(ctx, repoConfig); err != nil {
+				t.Fatal(err)
+			}
+			record := &devops.PipelineRecord{ConfigID: repoConfig.ID, Status: "success", CommitSHA: "0123abc"}
+			if err := s.repo.CreatePipelineRecord(ctx, record); err != nil {
+				t.Fatal(err)
+			}
+
+			NewCommitStatusService(s.repo).report(record)
+
+			reports, err := s.repo.ListCommitStatusReports(ctx, record.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var codes []int
+			for i, r := range reports {
+				codes = append(codes, r.StatusCode)
+				if r.Attempt != i+1 || r.Forge != "gitea" || r.State != "success" || r.CommitSHA != "0123abc" {
+					t.Errorf("report %d = %+v", i, r)
+				}
+				if failed := r.StatusCode < 200 || r.StatusCode >= 300; failed != (r.Error != "") {
+					t.Errorf("report %d has status %d and error %q", i, r.StatusCode, r.Error)
+				}
+			}
+			if !slices.Equal(codes, tt.wantCodes) {
+				t.Errorf("recorded status codes %v, want %v", codes, tt.wantCodes)
+			}
+			if !slices.Equal(*waits, tt.wantWaits) {
+				t.Errorf("waited %v, want %v", *waits, tt.wantWaits)
+			}
+			for _, a := range auth {
+				if a != "token tea123" {
+					t.Errorf("Authorization = %q", a)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveForgeSettingsKeepsToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       dto.ForgeRequest
+		wantToken string
+	}{
+		{name: "unchanged", req: dto.ForgeRequest{Type: "gitea", APIURL: "https://gitea.example.com/api/v1", Context: "deploy/prod"}, wantToken: "old"},
+		{name: "new token", req: dto.ForgeRequest{Type: "gitea", APIURL: "https://gitea.example.com/api/v1", Token: "new"}, wantToken: "new"},
+		{name: "other api url", req: dto.ForgeRequest{Type: "gitea", APIURL: "https://attacker.example.com/api/v1"}},
+		{name: "other forge", req: dto.ForgeRequest{Type: "github"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			ctx := context.Background()
+			repoConfig := &devops.RepoConfig{
+				Name:  "app",
+				Forge: devops.ForgeSettings{Type: "gitea", APIURL: "https://gitea.example.com/api/v1", Token: "old"},
+			}
+			if err := s.repo.SaveConfig(ctx, repoConfig); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := s.SaveForgeSettings(ctx, repoConfig.ID, tt.req); err != nil {
+				t.Fatal(err)
+			}
+			if got := s.repo.GetConfig(ctx, repoConfig.ID).Forge.Token; got != tt.wantToken {
+				t.Errorf("token = %q, want %q", got, tt.wantToken)
+			}
+		})
+	}
+}
@@ -4,101 +4,188 @@ import (
 	"OpsGo/internal/application/dto"
 	"OpsGo/internal/domain/entity/devops"
 	"OpsGo/internal/domain/repository"
-	"bufio"
+	"OpsGo/internal/infrastructure/ansi"
+	"OpsGo/internal/infrastructure/artifact"
+	"OpsGo/internal/infrastructure/config"
+	"OpsGo/internal/infrastructure/redis"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"os/exec"
+	"regexp"
+	"sync"
+	"syscall"
 	"time"
 )
 
+// scriptStopTimeout is how long a canceled deploy script may take to exit
+// after SIGTERM before it is killed.
+const scriptStopTimeout = 10 * time.Second
+
+// pipelineVarPrefix is the prefix of the variables a pipeline may add to the
+// environment of the deploy script.
+const pipelineVarPrefix = "OPSGO_VAR_"
+
+var pipelineVarName = regexp.MustCompile(`^` + pipelineVarPrefix + `[A-Za-z0-9_]+$`)
+
+// errPipelineClaimed is returned when another instance, or a cancel, changed
+// the status of a pipeline first.
+var errPipelineClaimed = errors.New("pipeline was claimed by another instance")
+
+// ErrPipelineNotFound is returned for pipeline IDs that don't exist.
+var ErrPipelineNotFound = errors.New("pipeline not found")
+
+// ErrPipelineStatus is returned for actions the status of the pipeline doesn't allow.
+var ErrPipelineStatus = errors.New("invalid pipeline status")
+
+// ErrInvalidEnv is returned for pipeline variables outside pipelineVarPrefix.
+var ErrInvalidEnv = errors.New("invalid pipeline env")
+
+// StatusListener is called after a pipeline changed status, with the stored record.
+type StatusListener func(record *devops.PipelineRecord)
+
 type DevOpsService struct {
 	repo        repository.DevOpsRepository
-	Broadcaster *LogBroadcaster
+	Broadcaster Broadcaster
+	artifacts   *artifact.Store
+	approvalMu  sync.Mutex
+	mirrorLocks sync.Map // config ID to the *sync.Mutex of its git mirror
+	runsMu      sync.Mutex
+	runs        map[uint64]*activeRun
+	retentionMu sync.Mutex
+	listeners   []StatusListener
+	stopChan    chan struct{}
 }
 
 func NewDevOpsService(repo repository.DevOpsRepository) *DevOpsService {
 	return &DevOpsService{
 		repo:        repo,
-		Broadcaster: NewLogBroadcaster(),
+		Broadcaster: newBroadcaster(),
+		artifacts:   artifact.NewStore(config.AppConfig.Artifacts.Dir),
+		runs:        make(map[uint64]*activeRun),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Stop terminates the background jobs started by the service.
+func (s *DevOpsService) Stop() {
+	close(s.stopChan)
+}
+
+// claimJob reports whether this instance runs a periodic job now. With the
+// Redis broadcaster several instances share the database, and the first to
+// take the job's lock runs it. The lock expires after ttl, a little less than
+// the job's interval, so that it is free again for the next run.
+func (s *DevOpsService) claimJob(name string, ttl time.Duration) bool {
+	if config.AppConfig.Broadcaster.Backend != "redis" || redis.Client == nil {
+		return true
+	}
+	hostname, _ := os.Hostname()
+	key := fmt.Sprintf("%s:job:%s", config.AppConfig.Broadcaster.Channel, name)
+	claimed, err := redis.Client.SetNX(context.Background(), key, hostname, ttl).Result()
+	if err != nil {
+		log.Printf("Failed to claim job %s: %v", name, err)
+		return false
+	}
+	return claimed
+}
+
+// OnStatusChange registers a listener for pipeline status changes. Listeners
+// must be registered before pipelines run and should return quickly.
+func (s *DevOpsService) OnStatusChange(listener StatusListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// publishStatus announces a status change to dashboard clients and listeners.
+func (s *DevOpsService) publishStatus(ctx context.Context, recordID uint64, status string) {
+	s.Broadcaster.BroadcastStatus(recordID, status)
+	if len(s.listeners) == 0 {
+		return
+	}
+
+	record := s.repo.GetPipelineRecord(ctx, recordID)
+	if record == nil {
+		return
+	}
+	for _, listener := range s.listeners {
+		listener(record)
 	}
 }
 
 func (s *DevOpsService) ConfigRepo(ctx context.Context, req dto.ConfigRepoRequest) (*dto.ConfigRepoResponse, error) {
 	config := &devops.RepoConfig{
-		Name:         req.Name,
-		RepoURL:      req.RepoURL,
-		DeployScript: req.DeployScript,
-		LogPath:      req.LogPath,
+		Name:            req.Name,
+		RepoURL:         req.RepoURL,
+		DeployScript:    req.DeployScript,
+		LogPath:         req.LogPath,
+		LogSources:      req.LogSources,
+		HealthCheck:     req.HealthCheck,
+		Retry:           req.Retry,
+		RequireApproval: req.RequireApproval,
+		ApprovalTimeout: req.ApprovalTimeout,
+	}
+
+	if err := validateLogSources(config.LogSources); err != nil {
+		return nil, err
 	}
 
 	// Check if exists
 	existing := s.repo.GetConfigByRepoURL(ctx, req.RepoURL)
 	if existing != nil {
 		config.ID = existing.ID
+		// Forge settings are only changed through SaveForgeSettings.
+		config.Forge = existing.Forge
 	}
 
 	if err := s.repo.SaveConfig(ctx, config); err != nil {
 		return nil, err
 	}
 
-	return &dto.ConfigRepoResponse{
-		ID:           config.ID,
-		RepoURL:      config.RepoURL,
-		DeployScript: config.DeployScript,
-	}, nil
+	resp := toConfigResponse(config)
+	return &resp, nil
 }
 
-func (s *DevOpsService) DeleteConfig(ctx context.Context, id uint64) error {
-	// Check if exists
+// SaveForgeSettings sets where the results of a service are reported as
+// commit statuses. A blank token keeps the stored one, unless the forge it
+// would be sent to changes.
+func (s *DevOpsService) SaveForgeSettings(ctx context.Context, id uint64, req dto.ForgeRequest) (*dto.ConfigRepoResponse, error) {
 	config := s.repo.GetConfig(ctx, id)
 	if config == nil {
-		return fmt.Errorf("config not found")
+		return nil, fmt.Errorf("config not found")
 	}
-	return s.repo.DeleteConfig(ctx, id)
-}
 
-func (s *DevOpsService) GetServiceLog(ctx context.Context, configID uint64) (string, error) {
-	config := s.repo.GetConfig(ctx, configID)
-	if config == nil {
-		return "", fmt.Errorf("config not found")
+	settings := devops.ForgeSettings{
+		Type:    req.Type,
+		APIURL:  req.APIURL,
+		Token:   req.Token,
+		Context: req.Context,
 	}
-
-	if config.LogPath == "" {
-		return "", fmt.Errorf("log path not configured")
+	if settings.Type == "gitea" && settings.APIURL == "" {
+		return nil, fmt.Errorf("forge api_url is required for gitea")
 	}
-
-	// Read last 5KB of log file
-	file, err := os.Open(config.LogPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open log file: %v", err)
-	}
-	defer file.Close()
-
-	stat, err := file.Stat()
-	if err != nil {
-		return "", err
+	if settings.Token == "" && settings.Type == config.Forge.Type && settings.APIURL == config.Forge.APIURL {
+		settings.Token = config.Forge.Token
 	}
 
-	fileSize := stat.Size()
-	readSize := int64(5120) // 5KB
-	if fileSize < readSize {
-		readSize = fileSize
+	config.Forge = settings
+	if err := s.repo.SaveConfig(ctx, config); err != nil {
+		return nil, err
 	}
 
-	offset := fileSize - readSize
-	if offset < 0 {
-		offset = 0
-	}
+	resp := toConfigResponse(config)
+	return &resp, nil
+}
 
-	buf := make([]byte, readSize)
-	_, err = file.ReadAt(buf, offset)
-	if err != nil && err != io.EOF {
-		return "", err
+func (s *DevOpsService) DeleteConfig(ctx context.Context, id uint64) error {
+	// Check if exists
+	config := s.repo.GetConfig(ctx, id)
+	if config == nil {
+		return fmt.Errorf("config not found")
 	}
-
-	return string(buf), nil
+	return s.repo.DeleteConfig(ctx, id)
 }
 
 func (s *DevOpsService) GetSummary(ctx context.Context) (*dto.DevOpsSummaryResponse, error) {
@@ -113,32 +200,13 @@ func (s *DevOpsService) GetSummary(ctx context.Context) (*dto.DevOpsSummaryRespo
 	}
 
 	var services []dto.ConfigRepoResponse
-	for _, c := range configs {
-		services = append(services, dto.ConfigRepoResponse{
-			ID:           c.ID,
-			Name:         c.Name,
-			RepoURL:      c.RepoURL,
-			DeployScript: c.DeployScript,
-			LogPath:      c.LogPath,
-		})
+	for i := range configs {
+		services = append(services, toConfigResponse(&configs[i]))
 	}
 
 	var pipelines []dto.PipelineRecordResponse
-	for _, p := range records {
-		pipelines = append(pipelines, dto.PipelineRecordResponse{
-			ID:            p.ID,
-			RepoName:      p.RepoName,
-			Status:        p.Status,
-			Ref:           p.Ref,
-			CommitSHA:     p.CommitSHA,
-			CommitMsg:     p.CommitMsg,
-			Author:        p.Author,
-			TriggerSource: p.TriggerSource,
-			Duration:      p.Duration,
-			StartedAt:     p.StartedAt,
-			FinishedAt:    p.FinishedAt,
-			CreatedAt:     p.CreatedAt,
-		})
+	for i := range records {
+		pipelines = append(pipelines, toPipelineResponse(&records[i]))
 	}
 
 	return &dto.DevOpsSummaryResponse{
@@ -147,29 +215,94 @@ func (s *DevOpsService) GetSummary(ctx context.Context) (*dto.DevOpsSummaryRespo
 	}, nil
 }
 
-func (s *DevOpsService) TriggerDeployment(ctx context.Context, configID uint64) error {
-	config := s.repo.GetConfig(ctx, configID)
-	if config == nil {
-		return fmt.Errorf("config not found")
+func toConfigResponse(c *devops.RepoConfig) dto.ConfigRepoResponse {
+	return dto.ConfigRepoResponse{
+		ID:              c.ID,
+		Name:            c.Name,
+		RepoURL:         c.RepoURL,
+		DeployScript:    c.DeployScript,
+		LogPath:         c.LogPath,
+		LogSources:      c.LogSources,
+		HealthCheck:     c.HealthCheck,
+		Retry:           c.Retry,
+		RequireApproval: c.RequireApproval,
+		ApprovalTimeout: c.ApprovalTimeout,
+		Forge:           c.Forge,
 	}
+}
 
-	record := &devops.PipelineRecord{
-		ConfigID:      config.ID,
-		RepoName:      config.Name,
-		Status:        "pending",
-		Ref:           "manual",
-		TriggerSource: "manual",
-		CreatedAt:     time.Now(),
+func toPipelineResponse(p *devops.PipelineRecord) dto.PipelineRecordResponse {
+	return dto.PipelineRecordResponse{
+		ID:             p.ID,
+		RepoName:       p.RepoName,
+		Status:         p.Status,
+		Ref:            p.Ref,
+		CommitSHA:      p.CommitSHA,
+		CommitMsg:      p.CommitMsg,
+		Author:         p.Author,
+		TriggerSource:  p.TriggerSource,
+		TriggeredBy:    p.TriggeredBy,
+		RollbackOf:     p.RollbackOf,
+		RerunOf:        p.RerunOf,
+		ScheduleID:     p.ScheduleID,
+		Args:           p.Args,
+		Approval:       p.Approval,
+		FreezeOverride: p.FreezeOverride,
+		ChangelogFrom:  p.ChangelogFrom,
+		Changelog:      p.Changelog,
+		Attempts:       p.Attempts,
+		Duration:       p.Duration,
+		StartedAt:      p.StartedAt,
+		FinishedAt:     p.FinishedAt,
+		CreatedAt:      p.CreatedAt,
 	}
+}
 
-	if err := s.repo.CreatePipelineRecord(ctx, record); err != nil {
-		return err
+func (s *DevOpsService) TriggerDeployment(ctx context.Context, req dto.TriggerDeploymentRequest, user string) (*devops.PipelineRecord, error) {
+	return s.triggerDeployment(ctx, req, user, devops.FreezeOverride{})
+}
+
+// TriggerDeploymentOverride deploys even if a freeze window is active; the
+// override is recorded on the pipeline.
+func (s *DevOpsService) TriggerDeploymentOverride(ctx context.Context, req dto.OverrideDeploymentRequest, user string) (*devops.PipelineRecord, error) {
+	return s.triggerDeployment(ctx, req.TriggerDeploymentRequest, user, devops.FreezeOverride{
+		By:     user,
+		Reason: req.Reason,
+	})
+}
+
+func (s *DevOpsService) triggerDeployment(ctx context.Context, req dto.TriggerDeploymentRequest, user string, override devops.FreezeOverride) (*devops.PipelineRecord, error) {
+	config := s.repo.GetConfig(ctx, req.ConfigID)
+	if config == nil {
+		return nil, fmt.Errorf("config not found")
+	}
+	if err := validatePipelineEnv(req.Env); err != nil {
+		return nil, err
 	}
 
-	// Trigger async deployment
-	go s.runDeployment(record.ID, config.DeployScript, "latest")
+	// Without an explicit ref the script deploys "latest", as it always has.
+	ref, arg := "manual", "latest"
+	if req.Ref != "" {
+		ref, arg = req.Ref, req.Ref
+	}
 
-	return nil
+	record := &devops.PipelineRecord{
+		ConfigID:       config.ID,
+		RepoName:       config.Name,
+		Status:         "pending",
+		Ref:            ref,
+		Args:           []string{arg},
+		Env:            req.Env,
+		TriggerSource:  "manual",
+		TriggeredBy:    user,
+		FreezeOverride: override,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := s.admitPipeline(ctx, config, record); err != nil {
+		return nil, err
+	}
+	return record, nil
 }
 
 func (s *DevOpsService) HandleCICallback(ctx context.Context, req dto.CICallbackRequest) error {
@@ -188,75 +321,374 @@ func (s *DevOpsService) HandleCICallback(ctx context.Context, req dto.CICallback
 		Status:        "pending",
 		Ref:           req.Tag,
 		CommitSHA:     req.CommitSHA,
+		Args:          []string{req.Tag},
 		TriggerSource: "ci_cd",
+		TriggeredBy:   "ci",
+		CreatedAt:     time.Now(),
+	}
+
+	return s.admitPipeline(ctx, config, record)
+}
+
+// RerunPipeline starts a new pipeline with the ref, SHA, script arguments and
+// environment of a past one.
+func (s *DevOpsService) RerunPipeline(ctx context.Context, id uint64, user string) (*devops.PipelineRecord, error) {
+	return s.clonePipeline(ctx, id, user, "rerun")
+}
+
+// RollbackToPipeline redeploys the release of a past successful pipeline.
+func (s *DevOpsService) RollbackToPipeline(ctx context.Context, id uint64, user string) (*devops.PipelineRecord, error) {
+	return s.clonePipeline(ctx, id, user, "rollback")
+}
+
+func (s *DevOpsService) clonePipeline(ctx context.Context, id uint64, user, source string) (*devops.PipelineRecord, error) {
+	past := s.repo.GetPipelineRecord(ctx, id)
+	if past == nil {
+		return nil, ErrPipelineNotFound
+	}
+	if source == "rollback" && past.Status != "success" {
+		return nil, fmt.Errorf("%w: can only roll back to a successful pipeline (status: %s)", ErrPipelineStatus, past.Status)
+	}
+	switch past.Status {
+	case "awaiting_approval", "rejected", "expired":
+		// Never approved, so there is nothing to run again.
+		return nil, fmt.Errorf("%w: cannot rerun a pipeline that was not approved (status: %s)", ErrPipelineStatus, past.Status)
+	}
+
+	config := s.repo.GetConfig(ctx, past.ConfigID)
+	if config == nil {
+		return nil, fmt.Errorf("config not found")
+	}
+
+	record := &devops.PipelineRecord{
+		ConfigID:      config.ID,
+		RepoName:      config.Name,
+		Status:        "pending",
+		Ref:           past.Ref,
+		CommitSHA:     past.CommitSHA,
+		Args:          pipelineArgs(past),
+		Env:           past.Env,
+		TriggerSource: source,
+		TriggeredBy:   user,
+		RerunOf:       past.ID,
 		CreatedAt:     time.Now(),
 	}
 
-	if err := s.repo.CreatePipelineRecord(ctx, record); err != nil {
+	if err := s.admitPipeline(ctx, config, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// startPipeline persists the pipeline record and runs it in the background.
+func (s *DevOpsService) startPipeline(ctx context.Context, config *devops.RepoConfig, record *devops.PipelineRecord) error {
+	if record.ID != 0 {
+		// Pipelines persisted earlier, such as queued ones every instance
+		// tries to release, are started by the instance claiming them.
+		claimed, err := s.repo.ClaimPipelineStatus(ctx, record.ID, record.Status, "pending")
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return errPipelineClaimed
+		}
+	}
+	record.Status = "pending"
+	if err := s.saveRecord(ctx, record); err != nil {
 		return err
 	}
 
 	// Trigger async deployment
-	go s.runDeployment(record.ID, config.DeployScript, req.Tag)
+	go s.runDeployment(record, config)
 
 	return nil
 }
 
-func (s *DevOpsService) runDeployment(recordID uint64, scriptPath string, args ...string) {
+// saveRecord creates new pipeline records and updates ones that were
+// persisted earlier, e.g. while queued by a freeze window.
+func (s *DevOpsService) saveRecord(ctx context.Context, record *devops.PipelineRecord) error {
+	if record.Token == "" {
+		record.Token = newPipelineToken()
+	}
+	if record.ID == 0 {
+		return s.repo.CreatePipelineRecord(ctx, record)
+	}
+	return s.repo.UpdatePipelineRecord(ctx, record)
+}
+
+func (s *DevOpsService) runDeployment(record *devops.PipelineRecord, config *devops.RepoConfig) {
 	ctx := context.Background()
+	recordID := record.ID
 	startTime := time.Now()
 
-	s.updateRecordStatus(ctx, recordID, "running", &startTime, nil, "")
-	s.Broadcaster.BroadcastStatus(recordID, "running")
+	runCtx, release := s.trackRun(recordID)
+	defer release()
+
+	out := &pipelineLog{}
+	if msg := s.resolveCommit(runCtx, record, config); msg != "" {
+		out.write(msg)
+		s.Broadcaster.BroadcastLog(recordID, msg)
+	}
+
+	s.updateRecordStatus(ctx, recordID, "running", &startTime, nil, nil)
+	s.publishStatus(ctx, recordID, "running")
+
+	artifactEnv := s.artifactEnv(ctx, record)
+
+	maxAttempts := config.Retry.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	status := "failed"
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if maxAttempts > 1 {
+			header := fmt.Sprintf("\n===== Attempt %d/%d =====\n", attempt, maxAttempts)
+			out.write(header)
+			s.Broadcaster.BroadcastLog(recordID, header)
+		}
+
+		result := s.runAttempt(runCtx, record, attempt, config.DeployScript, artifactEnv, out)
+		s.updateRecordAttempts(ctx, recordID, attempt)
+
+		if result.ExitCode == 0 && runCtx.Err() == nil {
+			status = "success"
+			break
+		}
+		if runCtx.Err() != nil || attempt == maxAttempts || !isRetryable(config.Retry, result.ExitCode) {
+			break
+		}
+
+		delay := retryBackoff(config.Retry, attempt)
+		msg := fmt.Sprintf("Exit code %d is retryable, retrying in %s\n", result.ExitCode, delay)
+		out.write(msg)
+		s.Broadcaster.BroadcastLog(recordID, msg)
+		select {
+		case <-time.After(delay):
+		case <-runCtx.Done():
+		}
+	}
+	if runCtx.Err() != nil {
+		status = "canceled"
+		msg := canceledMessage(s.canceledBy(recordID))
+		out.write(msg)
+		s.Broadcaster.BroadcastLog(recordID, msg)
+	}
+	if status == "success" {
+		s.collectArtifacts(ctx, record)
+	} else {
+		os.RemoveAll(s.stagingDir(recordID))
+	}
+	finishTime := time.Now()
+
+	if status == "success" && len(config.HealthCheck.Checks) > 0 {
+		if err := s.runHealthChecks(recordID, config.HealthCheck, out); err != nil {
+			status = "unhealthy"
+			msg := fmt.Sprintf("Health checks failed: %v\n", err)
+			out.write(msg)
+			s.Broadcaster.BroadcastLog(recordID, msg)
+		}
+		finishTime = time.Now()
+	}
+
+	s.updateRecordStatus(ctx, recordID, status, nil, &finishTime, out)
+
+	// Built before the release is recorded, which replaces the one to compare
+	// against, and before listeners are told, as notifications include it.
+	if status == "success" {
+		previous := s.repo.GetActiveRelease(ctx, config.ID)
+		if msg := s.attachChangelog(ctx, record, config, previous); msg != "" {
+			out.write(msg)
+			s.Broadcaster.BroadcastLog(recordID, msg)
+			s.updateRecordStatus(ctx, recordID, status, nil, nil, out)
+		}
+	}
+	s.publishStatus(ctx, recordID, status)
+
+	if status == "success" {
+		s.recordRelease(ctx, record, finishTime)
+	}
+
+	if status == "unhealthy" {
+		s.triggerRollback(ctx, recordID, config)
+	}
+}
+
+// runAttempt runs the deploy script once, streaming its output to clients and
+// out, and stores the attempt with its own log and exit code. Canceling ctx
+// stops the script and everything it started.
+func (s *DevOpsService) runAttempt(ctx context.Context, record *devops.PipelineRecord, attempt int, scriptPath string, extraEnv []string, out *pipelineLog) *devops.PipelineAttempt {
+	recordID := record.ID
+	startTime := time.Now()
+	result := &devops.PipelineAttempt{
+		PipelineID: recordID,
+		Attempt:    attempt,
+		StartedAt:  &startTime,
+	}
+	defer func() {
+		finishTime := time.Now()
+		result.FinishedAt = &finishTime
+		if err := s.repo.CreatePipelineAttempt(context.WithoutCancel(ctx), result); err != nil {
+			log.Printf("Failed to save attempt %d of pipeline %d: %v", attempt, recordID, err)
+		}
+	}()
 
 	// Prepend scriptPath to args to make it compatible with common execution patterns if needed,
 	// but exec.Command takes name (shell) and then args.
 	// We want to run: /bin/bash script_path arg1 arg2 ...
-	cmdArgs := append([]string{scriptPath}, args...)
-	cmd := exec.Command("/bin/bash", cmdArgs...)
+	cmdArgs := append([]string{scriptPath}, record.Args...)
+	cmd := exec.CommandContext(ctx, "/bin/bash", cmdArgs...)
+	cmd.Env = append(pipelineEnv(record), extraEnv...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = scriptStopTimeout
 
 	stdout, _ := cmd.StdoutPipe()
 	stderr, _ := cmd.StderrPipe()
 	multi := io.MultiReader(stdout, stderr)
 
 	if err := cmd.Start(); err != nil {
-		now := time.Now()
-		msg := fmt.Sprintf("Failed to start script: %v", err)
-		s.updateRecordStatus(ctx, recordID, "failed", nil, &now, msg)
+		msg := fmt.Sprintf("Failed to start script: %v\n", err)
+		result.ExitCode = -1
+		result.Log = msg
+		out.write(msg)
 		s.Broadcaster.BroadcastLog(recordID, msg)
-		s.Broadcaster.BroadcastStatus(recordID, "failed")
-		return
+		return result
 	}
 
 	// Stream logs
-	reader := bufio.NewReader(multi)
-	fullLog := ""
+	reader := ansi.NewReader(multi, config.AppConfig.PipelineLog.MaxLineLength)
 	for {
-		line, err := reader.ReadString('\n')
+		line, err := reader.ReadLine()
 		if line != "" {
-			fullLog += line
-			s.Broadcaster.BroadcastLog(recordID, line)
+			event := captureEvent(recordID, line)
+			result.Log += event.Content
+			out.write(event.Content)
+			s.Broadcaster.Broadcast(event)
 		}
 		if err != nil {
 			break
 		}
 	}
 
-	err := cmd.Wait()
-	finishTime := time.Now()
-	status := "success"
-	if err != nil {
-		status = "failed"
+	if err := cmd.Wait(); err != nil {
+		result.ExitCode = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			result.ExitCode = exitErr.ExitCode()
+		}
 		errMsg := fmt.Sprintf("\nCommand failed: %v\n", err)
-		fullLog += errMsg
+		result.Log += errMsg
+		out.write(errMsg)
 		s.Broadcaster.BroadcastLog(recordID, errMsg)
 	}
 
-	s.updateRecordStatus(ctx, recordID, status, nil, &finishTime, fullLog)
-	s.Broadcaster.BroadcastStatus(recordID, status)
+	return result
 }
 
-func (s *DevOpsService) updateRecordStatus(ctx context.Context, id uint64, status string, start *time.Time, finish *time.Time, logContent string) {
+// triggerRollback redeploys the last healthy release of the service after
+// recordID failed its health checks. Rollbacks never roll back themselves.
+func (s *DevOpsService) triggerRollback(ctx context.Context, recordID uint64, config *devops.RepoConfig) {
+	failed := s.repo.GetPipelineRecord(ctx, recordID)
+	if failed == nil {
+		return
+	}
+	if failed.TriggerSource == "rollback" {
+		s.Broadcaster.BroadcastLog(recordID, "Rollback pipeline is unhealthy, not rolling back again\n")
+		return
+	}
+
+	target := s.rollbackTarget(ctx, config.ID, failed.ID)
+	if target == nil {
+		s.Broadcaster.BroadcastLog(recordID, "No healthy release found, skipping rollback\n")
+		return
+	}
+
+	record := &devops.PipelineRecord{
+		ConfigID:       config.ID,
+		RepoName:       config.Name,
+		Status:         "pending",
+		Ref:            target.Ref,
+		CommitSHA:      target.CommitSHA,
+		Args:           pipelineArgs(target),
+		Env:            target.Env,
+		TriggerSource:  "rollback",
+		TriggeredBy:    "system",
+		RollbackOf:     failed.ID,
+		RerunOf:        target.ID,
+		ConfigSnapshot: config,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.saveRecord(ctx, record); err != nil {
+		s.Broadcaster.BroadcastLog(recordID, fmt.Sprintf("Failed to create rollback pipeline: %v\n", err))
+		return
+	}
+
+	s.Broadcaster.BroadcastLog(recordID, fmt.Sprintf("Rolling back to pipeline #%d (%s) as pipeline #%d\n", target.ID, target.Ref, record.ID))
+	s.Broadcaster.BroadcastLog(record.ID, fmt.Sprintf("Rollback of pipeline #%d to pipeline #%d (%s)\n", failed.ID, target.ID, target.Ref))
+
+	go s.runDeployment(record, config)
+}
+
+// rollbackTarget returns the pipeline of the active release, falling back to
+// the last successful pipeline for services deployed before releases existed.
+func (s *DevOpsService) rollbackTarget(ctx context.Context, configID, failedID uint64) *devops.PipelineRecord {
+	if release := s.repo.GetActiveRelease(ctx, configID); release != nil && release.PipelineID != failedID {
+		if target := s.repo.GetPipelineRecord(ctx, release.PipelineID); target != nil {
+			return target
+		}
+	}
+	return s.repo.GetLastSuccessfulPipeline(ctx, configID, failedID)
+}
+
+// pipelineArgs returns the arguments the deploy script was invoked with.
+// Records created before arguments were stored are reconstructed from the ref.
+func pipelineArgs(record *devops.PipelineRecord) []string {
+	if len(record.Args) > 0 {
+		return record.Args
+	}
+	if record.Ref == "manual" || record.Ref == "" {
+		return []string{"latest"}
+	}
+	return []string{record.Ref}
+}
+
+// validatePipelineEnv only accepts variables named OPSGO_VAR_*, so that
+// callers can't change how the deploy script runs (PATH, LD_PRELOAD,
+// BASH_ENV, ...).
+func validatePipelineEnv(env map[string]string) error {
+	for k := range env {
+		if !pipelineVarName.MatchString(k) {
+			return fmt.Errorf("%w: %q, variables must be named %s*", ErrInvalidEnv, k, pipelineVarPrefix)
+		}
+	}
+	return nil
+}
+
+// pipelineEnv returns the process environment for the deploy script: the
+// OpsGo environment, pipeline metadata and the pipeline's own variables.
+// Variables outside OPSGO_VAR_*, stored before they were validated, are
+// ignored.
+func pipelineEnv(record *devops.PipelineRecord) []string {
+	env := append(os.Environ(),
+		fmt.Sprintf("OPSGO_PIPELINE_ID=%d", record.ID),
+		"OPSGO_REF="+record.Ref,
+		"OPSGO_COMMIT_SHA="+record.CommitSHA,
+		"OPSGO_TRIGGER_SOURCE="+record.TriggerSource,
+		"OPSGO_PIPELINE_TOKEN="+record.Token,
+	)
+	for k, v := range record.Env {
+		if !pipelineVarName.MatchString(k) {
+			log.Printf("Ignoring env %q of pipeline %d", k, record.ID)
+			continue
+		}
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+func (s *DevOpsService) updateRecordStatus(ctx context.Context, id uint64, status string, start *time.Time, finish *time.Time, out *pipelineLog) {
 	record := s.repo.GetPipelineRecord(ctx, id)
 	if record == nil {
 		return
@@ -272,9 +704,20 @@ func (s *DevOpsService) updateRecordStatus(ctx context.Context, id uint64, statu
 			record.Duration = int64(finish.Sub(*record.StartedAt).Seconds())
 		}
 	}
-	if logContent != "" {
-		record.CommitMsg = logContent
+	if out != nil && out.Len() > 0 {
+		record.Log = out.String()
+		record.LogMarks = out.marks
+	}
+
+	s.repo.UpdatePipelineRecord(ctx, record)
+}
+
+func (s *DevOpsService) updateRecordAttempts(ctx context.Context, id uint64, attempts int) {
+	record := s.repo.GetPipelineRecord(ctx, id)
+	if record == nil {
+		return
 	}
 
+	record.Attempts = attempts
 	s.repo.UpdatePipelineRecord(ctx, record)
 }
@@ -0,0 +1,59 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/infrastructure/config"
+	devops_repo "OpsGo/internal/infrastructure/repository/devops"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestService returns a service on a fresh SQLite database, with data
+// directories in a temporary directory.
+func newTestService(t *testing.T) *DevOpsService {
+	t.Helper()
+	dir := t.TempDir()
+	config.AppConfig = &config.Config{}
+	config.AppConfig.Artifacts.Dir = filepath.Join(dir, "artifacts")
+	config.AppConfig.Retention.ArchiveDir = filepath.Join(dir, "archive")
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "opsgo.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(
+		&devops.RepoConfig{},
+		&devops.PipelineRecord{},
+		&devops.PipelineAttempt{},
+		&devops.FreezeWindow{},
+		&devops.DeploySchedule{},
+		&devops.PipelineArtifact{},
+		&devops.Release{},
+		&devops.CommitStatusReport{},
+		&devops.PipelineLogIndex{},
+		&devops.RetentionRun{},
+		&devops.NotificationChannel{},
+		&devops.NotificationRule{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := devops_repo.MigrateLogSearch(db); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	s := NewDevOpsService(devops_repo.NewDevOpsRepository(db))
+	t.Cleanup(s.Stop)
+	return s
+}
@@ -0,0 +1,261 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const freezeQueueInterval = time.Minute
+
+// ErrDeployFrozen is returned when a freeze window rejects a deploy.
+var ErrDeployFrozen = errors.New("deploys are frozen")
+
+// localTimeLayout is accepted besides RFC3339 for absolute times and is
+// interpreted in the timezone configured next to them.
+const localTimeLayout = "2006-01-02 15:04"
+
+func (s *DevOpsService) SaveFreezeWindow(ctx context.Context, req dto.FreezeWindowRequest) (*devops.FreezeWindow, error) {
+	loc, err := loadLocation(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	window := &devops.FreezeWindow{
+		ID:       req.ID,
+		Name:     req.Name,
+		ConfigID: req.ConfigID,
+		Cron:     req.Cron,
+		Duration: req.Duration,
+		Timezone: req.Timezone,
+		Action:   req.Action,
+		Enabled:  req.Enabled == nil || *req.Enabled,
+	}
+	if window.Action == "" {
+		window.Action = "reject"
+	}
+	if window.Action != "reject" && window.Action != "queue" {
+		return nil, fmt.Errorf("invalid action %q, expected reject or queue", window.Action)
+	}
+
+	switch {
+	case window.Cron != "":
+		if _, err := cron.ParseStandard(window.Cron); err != nil {
+			return nil, fmt.Errorf("invalid cron expression: %v", err)
+		}
+		if window.Duration <= 0 {
+			return nil, fmt.Errorf("duration is required for recurring windows")
+		}
+	case req.StartAt != "" && req.EndAt != "":
+		if window.StartAt, err = parseTimeIn(req.StartAt, loc); err != nil {
+			return nil, err
+		}
+		if window.EndAt, err = parseTimeIn(req.EndAt, loc); err != nil {
+			return nil, err
+		}
+		if !window.EndAt.After(*window.StartAt) {
+			return nil, fmt.Errorf("end_at must be after start_at")
+		}
+	default:
+		return nil, fmt.Errorf("either cron and duration or start_at and end_at are required")
+	}
+
+	if err := s.repo.SaveFreezeWindow(ctx, window); err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+func (s *DevOpsService) ListFreezeWindows(ctx context.Context) ([]devops.FreezeWindow, error) {
+	return s.repo.ListFreezeWindows(ctx)
+}
+
+func (s *DevOpsService) DeleteFreezeWindow(ctx context.Context, id uint64) error {
+	return s.repo.DeleteFreezeWindow(ctx, id)
+}
+
+// activeFreeze returns the first window blocking deploys of configID at now,
+// together with the time it ends.
+func (s *DevOpsService) activeFreeze(ctx context.Context, configID uint64, now time.Time) (*devops.FreezeWindow, time.Time, error) {
+	windows, err := s.repo.ListActiveFreezeWindows(ctx, configID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	for i := range windows {
+		end, active, err := freezeEnd(&windows[i], now)
+		if err != nil {
+			log.Printf("Skipping invalid freeze window %d: %v", windows[i].ID, err)
+			continue
+		}
+		if active {
+			return &windows[i], end, nil
+		}
+	}
+	return nil, time.Time{}, nil
+}
+
+// freezeEnd reports whether now falls inside the window and when it ends.
+func freezeEnd(window *devops.FreezeWindow, now time.Time) (time.Time, bool, error) {
+	if window.Cron == "" {
+		if window.StartAt == nil || window.EndAt == nil {
+			return time.Time{}, false, nil
+		}
+		active := !now.Before(*window.StartAt) && now.Before(*window.EndAt)
+		return *window.EndAt, active, nil
+	}
+
+	sched, err := cron.ParseStandard(window.Cron)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	loc, err := loadLocation(window.Timezone)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	// The window is active if a recurrence started within the last Duration.
+	duration := time.Duration(window.Duration) * time.Minute
+	start := sched.Next(now.In(loc).Add(-duration))
+	if start.After(now) {
+		return time.Time{}, false, nil
+	}
+	return start.Add(duration), true, nil
+}
+
+// admitPipeline applies freeze windows and the approval gate to a pipeline
+// before it runs. Records persisted earlier, queued by a freeze or approved,
+// are queued by any freeze window instead of being refused.
+func (s *DevOpsService) admitPipeline(ctx context.Context, config *devops.RepoConfig, record *devops.PipelineRecord) error {
+	if record.ConfigSnapshot == nil {
+		record.ConfigSnapshot = config
+	}
+
+	window, until, err := s.activeFreeze(ctx, config.ID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if window != nil {
+		if record.FreezeOverride.By != "" {
+			record.FreezeOverride.WindowID = window.ID
+			log.Printf("Freeze window %q overridden by %s for %s: %s", window.Name, record.FreezeOverride.By, config.Name, record.FreezeOverride.Reason)
+		} else if window.Action == "queue" || record.ID != 0 {
+			return s.queuePipeline(ctx, record, window, until)
+		} else {
+			return fmt.Errorf("%w by %q until %s", ErrDeployFrozen, window.Name, until.Format(time.RFC3339))
+		}
+	}
+
+	// Reruns and rollbacks are gated too, so that a rejected release can't
+	// be deployed by starting it again.
+	if config.RequireApproval && record.Approval.Decision != "approved" {
+		return s.awaitApproval(ctx, config, record)
+	}
+	if err := s.startPipeline(ctx, config, record); err != nil {
+		return err
+	}
+
+	if record.FreezeOverride.WindowID != 0 {
+		s.Broadcaster.BroadcastLog(record.ID, fmt.Sprintf("Freeze window %q overridden by %s: %s\n", window.Name, record.FreezeOverride.By, record.FreezeOverride.Reason))
+	}
+	return nil
+}
+
+func (s *DevOpsService) queuePipeline(ctx context.Context, record *devops.PipelineRecord, window *devops.FreezeWindow, until time.Time) error {
+	wasQueued := record.Status == "queued"
+	if record.ID != 0 && !wasQueued {
+		// An approved pipeline, which may be canceled meanwhile.
+		claimed, err := s.repo.ClaimPipelineStatus(ctx, record.ID, record.Status, "queued")
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return errPipelineClaimed
+		}
+	}
+	record.Status = "queued"
+	if err := s.saveRecord(ctx, record); err != nil {
+		return err
+	}
+
+	if !wasQueued {
+		s.Broadcaster.BroadcastLog(record.ID, fmt.Sprintf("Queued by freeze window %q until %s\n", window.Name, until.Format(time.RFC3339)))
+		s.publishStatus(ctx, record.ID, record.Status)
+	}
+	return nil
+}
+
+// StartFreezeQueue periodically releases pipelines queued by freeze windows
+// once no window blocks them anymore.
+func (s *DevOpsService) StartFreezeQueue() {
+	go func() {
+		ticker := time.NewTicker(freezeQueueInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.releaseQueuedPipelines()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+	log.Println("Freeze queue started")
+}
+
+func (s *DevOpsService) releaseQueuedPipelines() {
+	ctx := context.Background()
+	records, err := s.repo.ListPipelineRecordsByStatus(ctx, "queued")
+	if err != nil {
+		log.Printf("Failed to list queued pipelines: %v", err)
+		return
+	}
+
+	for i := range records {
+		record := &records[i]
+		config := s.repo.GetConfig(ctx, record.ConfigID)
+		if config == nil {
+			if claimed, err := s.repo.ClaimPipelineStatus(ctx, record.ID, "queued", "canceled"); err != nil || !claimed {
+				continue
+			}
+			now := time.Now()
+			out := &pipelineLog{}
+			out.write("Service was deleted while the pipeline was queued\n")
+			s.updateRecordStatus(ctx, record.ID, "canceled", nil, &now, out)
+			s.publishStatus(ctx, record.ID, "canceled")
+			continue
+		}
+		if err := s.admitPipeline(ctx, config, record); err != nil && !errors.Is(err, errPipelineClaimed) {
+			log.Printf("Failed to release queued pipeline %d: %v", record.ID, err)
+		}
+	}
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", name, err)
+	}
+	return loc, nil
+}
+
+func parseTimeIn(value string, loc *time.Location) (*time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if t, err = time.ParseInLocation(localTimeLayout, value, loc); err != nil {
+			return nil, fmt.Errorf("invalid time %q, expected RFC3339 or %q", value, localTimeLayout)
+		}
+	}
+	return &t, nil
+}
@@ -0,0 +1,135 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHealthTimeout  = 5 * time.Second
+	defaultHealthInterval = 5 * time.Second
+)
+
+// runHealthChecks probes every configured check until all of them pass in the
+// same round, the retries are used up or the deadline expires. Progress goes
+// to out and to the clients following the pipeline.
+func (s *DevOpsService) runHealthChecks(recordID uint64, policy devops.HealthCheckPolicy, out *pipelineLog) error {
+	retries := policy.Retries
+	if retries <= 0 {
+		retries = 1
+	}
+	interval := time.Duration(policy.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+
+	ctx := context.Background()
+	if policy.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(policy.Deadline)*time.Second)
+		defer cancel()
+	}
+
+	logf := func(format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		out.write(msg)
+		s.Broadcaster.BroadcastLog(recordID, msg)
+	}
+
+	logf("\nRunning %d health check(s)...\n", len(policy.Checks))
+
+	var lastErr error
+	for attempt := 1; attempt <= retries; attempt++ {
+		lastErr = nil
+		for i, check := range policy.Checks {
+			if err := probe(ctx, check); err != nil {
+				lastErr = fmt.Errorf("check #%d (%s): %v", i+1, check.Type, err)
+				break
+			}
+		}
+
+		if lastErr == nil {
+			logf("Health checks passed (attempt %d/%d)\n", attempt, retries)
+			return nil
+		}
+		logf("Health check attempt %d/%d failed: %v\n", attempt, retries, lastErr)
+
+		if attempt == retries {
+			break
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return fmt.Errorf("health check deadline exceeded: %v", lastErr)
+		}
+	}
+
+	return lastErr
+}
+
+func probe(ctx context.Context, check devops.HealthCheck) error {
+	timeout := time.Duration(check.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultHealthTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch check.Type {
+	case "http":
+		return probeHTTP(ctx, check)
+	case "tcp":
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", check.Address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case "command":
+		out, err := exec.CommandContext(ctx, "/bin/bash", "-c", check.Command).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown health check type %q", check.Type)
+	}
+}
+
+func probeHTTP(ctx context.Context, check devops.HealthCheck) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, check.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	expectStatus := check.ExpectStatus
+	if expectStatus == 0 {
+		expectStatus = http.StatusOK
+	}
+	if resp.StatusCode != expectStatus {
+		return fmt.Errorf("expected status %d, got %d", expectStatus, resp.StatusCode)
+	}
+
+	if check.ExpectBody != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(body), check.ExpectBody) {
+			return fmt.Errorf("response body does not contain %q", check.ExpectBody)
+		}
+	}
+	return nil
+}
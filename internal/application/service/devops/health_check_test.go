@@ -0,0 +1,62 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthCheckOutcomeIsBroadcast(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	script := filepath.Join(t.TempDir(), "deploy.sh")
+	if err := os.WriteFile(script, []byte("exit 0\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	repoConfig := &devops.RepoConfig{
+		Name:         "app",
+		DeployScript: script,
+		HealthCheck: devops.HealthCheckPolicy{
+			Checks: []devops.HealthCheck{{Type: "command", Command: "echo down; exit 3"}},
+		},
+	}
+	if err := s.repo.SaveConfig(ctx, repoConfig); err != nil {
+		t.Fatal(err)
+	}
+	record := &devops.PipelineRecord{ConfigID: repoConfig.ID, RepoName: "app", Status: "pending"}
+	if err := s.repo.CreatePipelineRecord(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	client := s.Broadcaster.Register()
+	defer s.Broadcaster.Unregister(client)
+	s.runDeployment(record, repoConfig)
+
+	var streamed strings.Builder
+	for done := false; !done; {
+		select {
+		case event := <-client:
+			if event.PipelineID != record.ID {
+				continue
+			}
+			streamed.WriteString(event.Content)
+			done = event.Type == "status" && event.Status == "unhealthy"
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no unhealthy status streamed, got:\n%s", streamed.String())
+		}
+	}
+
+	const want = "Health checks failed: check #1 (command): exit status 3: down\n"
+	if !strings.Contains(streamed.String(), want) {
+		t.Errorf("streamed log:\n%s\nwant it to contain %q", streamed.String(), want)
+	}
+	stored := s.repo.GetPipelineRecord(ctx, record.ID)
+	if stored.Status != "unhealthy" || !strings.Contains(stored.Log, "Health check attempt 1/1 failed") || !strings.Contains(stored.Log, want) {
+		t.Errorf("stored pipeline %s with log:\n%s", stored.Status, stored.Log)
+	}
+}
@@ -0,0 +1,264 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/domain/repository"
+	"OpsGo/internal/infrastructure/config"
+	"OpsGo/internal/infrastructure/notify"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+const notificationTimeout = 30 * time.Second
+
+// finalStatuses are the statuses a rule without explicit statuses notifies on.
+var finalStatuses = []string{"success", "failed", "unhealthy", "canceled", "rejected", "expired"}
+
+var pipelineStatuses = []string{"queued", "awaiting_approval", "pending", "running", "success", "failed", "unhealthy", "canceled", "rejected", "expired"}
+
+// NotificationService delivers pipeline status changes to the channels
+// selected by the notification rules.
+type NotificationService struct {
+	repo repository.DevOpsRepository
+}
+
+func NewNotificationService(repo repository.DevOpsRepository) *NotificationService {
+	return &NotificationService{repo: repo}
+}
+
+func (s *NotificationService) SaveChannel(ctx context.Context, req dto.NotificationChannelRequest) (*devops.NotificationChannel, error) {
+	channel := &devops.NotificationChannel{
+		ID:         req.ID,
+		Name:       req.Name,
+		Type:       req.Type,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		Recipients: req.Recipients,
+		Template:   req.Template,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+	if req.ID != 0 {
+		existing := s.repo.GetNotificationChannel(ctx, req.ID)
+		if existing == nil {
+			return nil, fmt.Errorf("channel not found")
+		}
+		if channel.Secret == "" {
+			channel.Secret = existing.Secret
+		}
+		channel.CreatedAt = existing.CreatedAt
+	}
+
+	if channel.Type == "email" {
+		if len(channel.Recipients) == 0 {
+			return nil, fmt.Errorf("recipients are required for email channels")
+		}
+	} else if !strings.HasPrefix(channel.URL, "http://") && !strings.HasPrefix(channel.URL, "https://") {
+		return nil, fmt.Errorf("a http(s) url is required for %s channels", channel.Type)
+	}
+
+	if channel.Template != "" {
+		if _, err := parseNotificationTemplate(channel.Template); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.SaveNotificationChannel(ctx, channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func (s *NotificationService) ListChannels(ctx context.Context) ([]devops.NotificationChannel, error) {
+	return s.repo.ListNotificationChannels(ctx)
+}
+
+func (s *NotificationService) DeleteChannel(ctx context.Context, id uint64) error {
+	if s.repo.GetNotificationChannel(ctx, id) == nil {
+		return fmt.Errorf("channel not found")
+	}
+	return s.repo.DeleteNotificationChannel(ctx, id)
+}
+
+// TestChannel sends a sample message once, without retries, and reports the result.
+func (s *NotificationService) TestChannel(ctx context.Context, id uint64) error {
+	channel := s.repo.GetNotificationChannel(ctx, id)
+	if channel == nil {
+		return fmt.Errorf("channel not found")
+	}
+	sender, err := newSender(channel)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notificationTimeout)
+	defer cancel()
+	return sender.Send(ctx, notify.Message{
+		Event:   "notification.test",
+		Service: "opsgo",
+		Status:  "test",
+		Ref:     "-",
+		Time:    time.Now(),
+	})
+}
+
+func (s *NotificationService) SaveRule(ctx context.Context, req dto.NotificationRuleRequest) (*devops.NotificationRule, error) {
+	if s.repo.GetNotificationChannel(ctx, req.ChannelID) == nil {
+		return nil, fmt.Errorf("channel not found")
+	}
+	if req.ConfigID != 0 && s.repo.GetConfig(ctx, req.ConfigID) == nil {
+		return nil, fmt.Errorf("config not found")
+	}
+	for _, status := range req.Statuses {
+		if !slices.Contains(pipelineStatuses, status) {
+			return nil, fmt.Errorf("unknown status %q", status)
+		}
+	}
+
+	rule := &devops.NotificationRule{
+		ID:        req.ID,
+		ChannelID: req.ChannelID,
+		ConfigID:  req.ConfigID,
+		Statuses:  req.Statuses,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+	}
+	if err := s.repo.SaveNotificationRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *NotificationService) ListRules(ctx context.Context) ([]devops.NotificationRule, error) {
+	return s.repo.ListNotificationRules(ctx)
+}
+
+func (s *NotificationService) DeleteRule(ctx context.Context, id uint64) error {
+	return s.repo.DeleteNotificationRule(ctx, id)
+}
+
+// Notify is a StatusListener; delivery happens in the background.
+func (s *NotificationService) Notify(record *devops.PipelineRecord) {
+	go s.dispatch(record)
+}
+
+func (s *NotificationService) dispatch(record *devops.PipelineRecord) {
+	ctx := context.Background()
+	rules, err := s.repo.ListEnabledNotificationRules(ctx, record.ConfigID)
+	if err != nil {
+		log.Printf("Failed to load notification rules: %v", err)
+		return
+	}
+
+	msg := buildNotification(record)
+	notified := make(map[uint64]bool)
+	for _, rule := range rules {
+		if notified[rule.ChannelID] || !ruleMatches(rule, record.Status) {
+			continue
+		}
+		notified[rule.ChannelID] = true
+
+		channel := s.repo.GetNotificationChannel(ctx, rule.ChannelID)
+		if channel == nil || !channel.Enabled {
+			continue
+		}
+		sender, err := newSender(channel)
+		if err != nil {
+			log.Printf("Notification channel %d: %v", channel.ID, err)
+			continue
+		}
+
+		channelMsg := msg
+		if channel.Template != "" {
+			// A broken template still notifies, with the default format.
+			if channelMsg.Body, err = renderNotification(channel.Template, s.templateData(ctx, record, msg.Link)); err != nil {
+				log.Printf("Notification channel %q: %v", channel.Name, err)
+			}
+		}
+		go deliver(sender, channel, channelMsg)
+	}
+}
+
+// deliver sends msg, retrying with exponential backoff.
+func deliver(sender notify.Sender, channel *devops.NotificationChannel, msg notify.Message) {
+	cfg := config.AppConfig.Notify
+	backoff := time.Duration(cfg.Backoff) * time.Second
+
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+		err := sender.Send(ctx, msg)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt >= cfg.MaxAttempts {
+			log.Printf("Failed to notify channel %q about pipeline %d after %d attempts: %v", channel.Name, msg.PipelineID, attempt, err)
+			return
+		}
+		log.Printf("Notification to channel %q failed (attempt %d), retrying in %s: %v", channel.Name, attempt, backoff, err)
+		sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func ruleMatches(rule devops.NotificationRule, status string) bool {
+	if len(rule.Statuses) == 0 {
+		return slices.Contains(finalStatuses, status)
+	}
+	return slices.Contains(rule.Statuses, status)
+}
+
+func newSender(channel *devops.NotificationChannel) (notify.Sender, error) {
+	switch channel.Type {
+	case "webhook":
+		return notify.NewWebhook(channel.URL, channel.Secret), nil
+	case "slack":
+		return notify.NewSlack(channel.URL), nil
+	case "dingtalk":
+		return notify.NewDingTalk(channel.URL, channel.Secret), nil
+	case "feishu":
+		return notify.NewFeishu(channel.URL, channel.Secret), nil
+	case "email":
+		smtp := config.AppConfig.Notify.SMTP
+		return notify.NewEmail(notify.SMTPSettings{
+			Host:     smtp.Host,
+			Port:     smtp.Port,
+			Username: smtp.Username,
+			Password: smtp.Password,
+			From:     smtp.From,
+		}, channel.Recipients), nil
+	default:
+		return nil, fmt.Errorf("unknown channel type %q", channel.Type)
+	}
+}
+
+func buildNotification(record *devops.PipelineRecord) notify.Message {
+	msg := notify.Message{
+		Event:       "pipeline." + record.Status,
+		PipelineID:  record.ID,
+		ServiceID:   record.ConfigID,
+		Service:     record.RepoName,
+		Status:      record.Status,
+		Ref:         record.Ref,
+		CommitSHA:   record.CommitSHA,
+		TriggeredBy: record.TriggeredBy,
+		Source:      record.TriggerSource,
+		Duration:    record.Duration,
+		Changelog:   []notify.Commit{},
+		Time:        time.Now(),
+	}
+	if base := strings.TrimSuffix(config.AppConfig.Notify.BaseURL, "/"); base != "" {
+		msg.Link = fmt.Sprintf("%s%s/pipelines/%d", base, apiPrefix, record.ID)
+	}
+	for _, c := range record.Changelog {
+		msg.Changelog = append(msg.Changelog, notify.Commit{
+			SHA:     c.SHA,
+			Author:  c.Author,
+			Subject: c.Subject,
+		})
+	}
+	return msg
+}
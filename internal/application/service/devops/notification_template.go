@@ -0,0 +1,220 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+const notificationLogTailLines = 20
+
+// NotificationTemplateData is the data channel templates are executed with.
+// Templates are written by admins but their output leaves OpsGo, so secrets
+// such as the pipeline token and forge credentials are left out.
+type NotificationTemplateData struct {
+	Event     string // pipeline.<status>
+	Status    string
+	Pipeline  NotificationPipeline
+	Config    NotificationConfig // service config at trigger time, or the current one
+	Duration  time.Duration
+	Changelog []devops.ChangelogCommit // commits since the previous release
+	LogTail   string                   // last lines of the pipeline log
+	Link      string                   // pipeline URL, empty without notify.base_url
+}
+
+// NotificationPipeline is a pipeline as templates see it, without its token
+// and environment.
+type NotificationPipeline struct {
+	ID             uint64
+	ConfigID       uint64
+	RepoName       string
+	Status         string
+	Ref            string
+	CommitSHA      string
+	CommitMsg      string
+	Author         string
+	TriggeredBy    string
+	TriggerSource  string
+	RollbackOf     uint64
+	RerunOf        uint64
+	ScheduleID     uint64
+	Args           []string
+	Approval       devops.PipelineApproval
+	FreezeOverride devops.FreezeOverride
+	ChangelogFrom  string
+	Attempts       int
+	Log            string // the full log, also when archived
+	Duration       int64  // seconds
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
+	CreatedAt      time.Time
+}
+
+// NotificationConfig is a service config as templates see it, without forge
+// credentials or credentials in the repository URL.
+type NotificationConfig struct {
+	ID              uint64
+	Name            string
+	RepoURL         string
+	DeployScript    string
+	RequireApproval bool
+	Forge           NotificationForge
+}
+
+type NotificationForge struct {
+	Type    string
+	APIURL  string
+	Context string
+}
+
+func newNotificationPipeline(record *devops.PipelineRecord, logText string) NotificationPipeline {
+	return NotificationPipeline{
+		ID:             record.ID,
+		ConfigID:       record.ConfigID,
+		RepoName:       record.RepoName,
+		Status:         record.Status,
+		Ref:            record.Ref,
+		CommitSHA:      record.CommitSHA,
+		CommitMsg:      record.CommitMsg,
+		Author:         record.Author,
+		TriggeredBy:    record.TriggeredBy,
+		TriggerSource:  record.TriggerSource,
+		RollbackOf:     record.RollbackOf,
+		RerunOf:        record.RerunOf,
+		ScheduleID:     record.ScheduleID,
+		Args:           record.Args,
+		Approval:       record.Approval,
+		FreezeOverride: record.FreezeOverride,
+		ChangelogFrom:  record.ChangelogFrom,
+		Attempts:       record.Attempts,
+		Log:            logText,
+		Duration:       record.Duration,
+		StartedAt:      record.StartedAt,
+		FinishedAt:     record.FinishedAt,
+		CreatedAt:      record.CreatedAt,
+	}
+}
+
+func newNotificationConfig(repoConfig *devops.RepoConfig) NotificationConfig {
+	repoURL := repoConfig.RepoURL
+	if u, err := url.Parse(repoURL); err == nil && u.User != nil {
+		u.User = nil
+		repoURL = u.String()
+	}
+	return NotificationConfig{
+		ID:              repoConfig.ID,
+		Name:            repoConfig.Name,
+		RepoURL:         repoURL,
+		DeployScript:    repoConfig.DeployScript,
+		RequireApproval: repoConfig.RequireApproval,
+		Forge: NotificationForge{
+			Type:    repoConfig.Forge.Type,
+			APIURL:  repoConfig.Forge.APIURL,
+			Context: repoConfig.Forge.Context,
+		},
+	}
+}
+
+var notificationFuncs = template.FuncMap{
+	"short": func(sha string) string {
+		if len(sha) > 7 {
+			return sha[:7]
+		}
+		return sha
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  strings.Join,
+	// truncate cuts s after n characters.
+	"truncate": func(n int, s string) string {
+		if n < 0 || utf8.RuneCountInString(s) <= n {
+			return s
+		}
+		return string([]rune(s)[:n]) + "..."
+	},
+}
+
+func parseNotificationTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("notification").Funcs(notificationFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+	return tmpl, nil
+}
+
+func renderNotification(text string, data *NotificationTemplateData) (string, error) {
+	tmpl, err := parseNotificationTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %v", err)
+	}
+	return buf.String(), nil
+}
+
+func (s *NotificationService) templateData(ctx context.Context, record *devops.PipelineRecord, link string) *NotificationTemplateData {
+	repoConfig := record.ConfigSnapshot
+	if repoConfig == nil {
+		repoConfig = s.repo.GetConfig(ctx, record.ConfigID)
+	}
+	if repoConfig == nil {
+		repoConfig = &devops.RepoConfig{ID: record.ConfigID, Name: record.RepoName}
+	}
+	// An unreadable log archive leaves the tail empty.
+	logText, _ := readPipelineLog(record)
+
+	return &NotificationTemplateData{
+		Event:     "pipeline." + record.Status,
+		Status:    record.Status,
+		Pipeline:  newNotificationPipeline(record, logText),
+		Config:    newNotificationConfig(repoConfig),
+		Duration:  time.Duration(record.Duration) * time.Second,
+		Changelog: record.Changelog,
+		LogTail:   logTail(logText, notificationLogTailLines),
+		Link:      link,
+	}
+}
+
+// PreviewNotification renders a template, or the template of a channel,
+// against a past pipeline.
+func (s *NotificationService) PreviewNotification(ctx context.Context, req dto.NotificationPreviewRequest) (string, error) {
+	record := s.repo.GetPipelineRecord(ctx, req.PipelineID)
+	if record == nil {
+		return "", ErrPipelineNotFound
+	}
+
+	text := req.Template
+	if text == "" && req.ChannelID != 0 {
+		channel := s.repo.GetNotificationChannel(ctx, req.ChannelID)
+		if channel == nil {
+			return "", fmt.Errorf("channel not found")
+		}
+		text = channel.Template
+	}
+
+	msg := buildNotification(record)
+	if text == "" {
+		return msg.Text(), nil
+	}
+	return renderNotification(text, s.templateData(ctx, record, msg.Link))
+}
+
+// logTail returns the last n lines of a log.
+func logTail(log string, n int) string {
+	log = strings.TrimRight(log, "\n")
+	lines := strings.Split(log, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
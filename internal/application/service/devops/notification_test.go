@@ -0,0 +1,149 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/infrastructure/config"
+	"OpsGo/internal/infrastructure/notify"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// flakySender fails the first failures sends.
+type flakySender struct {
+	failures int
+	sent     int
+}
+
+func (f *flakySender) Send(ctx context.Context, msg notify.Message) error {
+	f.sent++
+	if f.sent <= f.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// recordSleeps replaces sleep for the test and returns the waits.
+func recordSleeps(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	t.Cleanup(func() { sleep = time.Sleep })
+	return &waits
+}
+
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		wantSent  int
+		wantWaits []time.Duration
+	}{
+		{name: "first attempt", failures: 0, wantSent: 1},
+		{name: "retried", failures: 2, wantSent: 3, wantWaits: []time.Duration{5 * time.Second, 10 * time.Second}},
+		{name: "gives up", failures: 5, wantSent: 4, wantWaits: []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.AppConfig = &config.Config{}
+			config.AppConfig.Notify.MaxAttempts = 4
+			config.AppConfig.Notify.Backoff = 5
+			waits := recordSleeps(t)
+
+			sender := &flakySender{failures: tt.failures}
+			deliver(sender, &devops.NotificationChannel{Name: "ops"}, notify.Message{PipelineID: 1})
+
+			if sender.sent != tt.wantSent {
+				t.Errorf("sent %d times, want %d", sender.sent, tt.wantSent)
+			}
+			if !slices.Equal(*waits, tt.wantWaits) {
+				t.Errorf("waited %v, want %v", *waits, tt.wantWaits)
+			}
+		})
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		status   string
+		want     bool
+	}{
+		{name: "listed", statuses: []string{"failed", "unhealthy"}, status: "failed", want: true},
+		{name: "not listed", statuses: []string{"failed", "unhealthy"}, status: "success", want: false},
+		{name: "listed intermediate", statuses: []string{"awaiting_approval"}, status: "awaiting_approval", want: true},
+		{name: "default final", status: "success", want: true},
+		{name: "default expired", status: "expired", want: true},
+		{name: "default running", status: "running", want: false},
+		{name: "default queued", status: "queued", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := devops.NotificationRule{Statuses: tt.statuses, Enabled: true}
+			if got := ruleMatches(rule, tt.status); got != tt.want {
+				t.Errorf("ruleMatches(%v, %q) = %v, want %v", tt.statuses, tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatchNotifiesMatchingChannelsOnce(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	config.AppConfig.Notify.MaxAttempts = 1
+
+	received := make(chan notify.Message, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var msg notify.Message
+		json.Unmarshal(body, &msg)
+		received <- msg
+	}))
+	defer srv.Close()
+
+	other := &devops.RepoConfig{Name: "other", RepoURL: "https://example.com/o/other.git"}
+	app := &devops.RepoConfig{Name: "app", RepoURL: "https://example.com/o/app.git"}
+	for _, c := range []*devops.RepoConfig{other, app} {
+		if err := s.repo.SaveConfig(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	channel := &devops.NotificationChannel{Name: "hook", Type: "webhook", URL: srv.URL, Enabled: true}
+	if err := s.repo.SaveNotificationChannel(ctx, channel); err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range []*devops.NotificationRule{
+		{ChannelID: channel.ID, ConfigID: app.ID, Statuses: []string{"failed"}, Enabled: true},
+		{ChannelID: channel.ID, Enabled: true}, // every service, final statuses
+		{ChannelID: channel.ID, ConfigID: other.ID, Statuses: []string{"failed"}, Enabled: true},
+	} {
+		if err := s.repo.SaveNotificationRule(ctx, rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ns := NewNotificationService(s.repo)
+	ns.dispatch(&devops.PipelineRecord{ID: 7, ConfigID: app.ID, RepoName: "app", Status: "failed"})
+	ns.dispatch(&devops.PipelineRecord{ID: 8, ConfigID: app.ID, RepoName: "app", Status: "running"})
+
+	select {
+	case msg := <-received:
+		if msg.PipelineID != 7 || msg.Event != "pipeline.failed" {
+			t.Errorf("notified %+v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification sent")
+	}
+	select {
+	case msg := <-received:
+		t.Errorf("extra notification %+v", msg)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
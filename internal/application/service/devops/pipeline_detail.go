@@ -0,0 +1,67 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"context"
+	"fmt"
+)
+
+const apiPrefix = "/api/v1/devops"
+
+// GetPipelineDetail returns one pipeline with everything needed to act on it.
+func (s *DevOpsService) GetPipelineDetail(ctx context.Context, id uint64) (*dto.PipelineDetailResponse, error) {
+	record := s.repo.GetPipelineRecord(ctx, id)
+	if record == nil {
+		return nil, ErrPipelineNotFound
+	}
+
+	attempts, err := s.repo.ListPipelineAttempts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.Log, err = readPipelineLog(record); err != nil {
+		return nil, err
+	}
+
+	pipeline := toPipelineResponse(record)
+	pipeline.Env = record.Env
+	pipeline.Log = record.Log
+	resp := &dto.PipelineDetailResponse{
+		Pipeline:    pipeline,
+		Attempts:    make([]dto.PipelineAttemptResponse, 0, len(attempts)),
+		LogSize:     len(record.Log),
+		TriggeredBy: record.TriggeredBy,
+		Links: map[string]string{
+			"self":  fmt.Sprintf("%s/pipelines/%d", apiPrefix, record.ID),
+			"rerun": fmt.Sprintf("%s/pipelines/%d/rerun", apiPrefix, record.ID),
+		},
+	}
+
+	if record.ConfigSnapshot != nil {
+		config := toConfigResponse(record.ConfigSnapshot)
+		resp.Config = &config
+		resp.ConfigSnapshot = true
+	} else if current := s.repo.GetConfig(ctx, record.ConfigID); current != nil {
+		config := toConfigResponse(current)
+		resp.Config = &config
+	}
+
+	if resp.TriggeredBy == "" {
+		resp.TriggeredBy = record.TriggerSource
+	}
+	if record.Status == "success" {
+		resp.Links["rollback"] = fmt.Sprintf("%s/pipelines/%d/rollback", apiPrefix, record.ID)
+	}
+
+	for _, a := range attempts {
+		resp.Attempts = append(resp.Attempts, dto.PipelineAttemptResponse{
+			Attempt:    a.Attempt,
+			ExitCode:   a.ExitCode,
+			LogSize:    len(a.Log),
+			StartedAt:  a.StartedAt,
+			FinishedAt: a.FinishedAt,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,207 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/domain/repository"
+	"OpsGo/internal/infrastructure/ansi"
+	"OpsGo/internal/infrastructure/config"
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const logExportBatchSize = 100
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// pipelineLog accumulates the log of a pipeline and marks the times its lines
+// were written at.
+type pipelineLog struct {
+	strings.Builder
+	lines int // newlines written
+	marks []devops.LogMark
+}
+
+func (l *pipelineLog) write(text string) {
+	if text == "" {
+		return
+	}
+	now := time.Now().Unix()
+	line := l.lines + 1
+	if n := len(l.marks); n == 0 || (l.marks[n-1].Time != now && l.marks[n-1].Line != line) {
+		l.marks = append(l.marks, devops.LogMark{Line: line, Time: now})
+	}
+	l.WriteString(text)
+	l.lines += strings.Count(text, "\n")
+}
+
+// captureEvent turns a line of deploy script output into a log event, its
+// escape sequences handled as pipeline_log.ansi says.
+func captureEvent(pipelineID uint64, line string) LogEvent {
+	event := LogEvent{Type: "log", PipelineID: pipelineID, Content: line}
+	switch config.AppConfig.PipelineLog.ANSI {
+	case "spans":
+		event.Content, event.Spans = ansi.Parse(line)
+	case "strip":
+		event.Content = ansi.Strip(line)
+	}
+	return event
+}
+
+// GetPipelineLog returns a pipeline and its full log, optionally with the time
+// each line was written at in front of it.
+func (s *DevOpsService) GetPipelineLog(ctx context.Context, id uint64, timestamps bool) (*devops.PipelineRecord, string, error) {
+	record := s.repo.GetPipelineRecord(ctx, id)
+	if record == nil {
+		return nil, "", ErrPipelineNotFound
+	}
+	text, err := readPipelineLog(record)
+	if err != nil {
+		return nil, "", err
+	}
+	if timestamps {
+		text = timestampLog(text, record.LogMarks)
+	}
+	return record, text, nil
+}
+
+// timestampLog prepends the RFC 3339 time of each line, as docker logs
+// --timestamps does. Lines before the first mark, such as all the lines of
+// pipelines that ran before marks were recorded, are left as they are.
+func timestampLog(text string, marks []devops.LogMark) string {
+	if len(marks) == 0 {
+		return text
+	}
+	var b strings.Builder
+	b.Grow(len(text) + 26*strings.Count(text, "\n"))
+	prefix := ""
+	next := 0
+	for i, line := range strings.SplitAfter(text, "\n") {
+		if line == "" {
+			continue
+		}
+		for next < len(marks) && marks[next].Line <= i+1 {
+			prefix = time.Unix(marks[next].Time, 0).Format(time.RFC3339) + " "
+			next++
+		}
+		b.WriteString(prefix)
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// LogExport is a tar.gz of the pipeline logs of a service, see
+// ExportServiceLogs.
+type LogExport struct {
+	Filename   string
+	s          *DevOpsService
+	dir        string
+	filter     repository.PipelineFilter
+	timestamps bool
+}
+
+// ExportServiceLogs prepares an export of the logs of the pipelines of a
+// service created in a date range.
+func (s *DevOpsService) ExportServiceLogs(ctx context.Context, configID uint64, req dto.LogExportRequest) (*LogExport, error) {
+	repoConfig := s.repo.GetConfig(ctx, configID)
+	if repoConfig == nil {
+		return nil, fmt.Errorf("config not found")
+	}
+
+	filter := repository.PipelineFilter{ConfigID: configID, SortBy: "id", Limit: logExportBatchSize}
+	var err error
+	if filter.From, err = parseDateParam(req.From, false); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseDateParam(req.To, true); err != nil {
+		return nil, err
+	}
+
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(repoConfig.Name, "-"), "-")
+	if name == "" {
+		name = fmt.Sprintf("service-%d", repoConfig.ID)
+	}
+	dir := fmt.Sprintf("%s-logs-%s", name, time.Now().Format("20060102-150405"))
+	return &LogExport{
+		Filename:   dir + ".tar.gz",
+		s:          s,
+		dir:        dir,
+		filter:     filter,
+		timestamps: req.Timestamps,
+	}, nil
+}
+
+// Write streams the archive to w: a <pipeline id>.log file per pipeline,
+// oldest first, and pipelines.json describing them.
+func (e *LogExport) Write(ctx context.Context, w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+
+	pipelines := []dto.PipelineRecordResponse{}
+	filter := e.filter
+	for {
+		records, _, err := e.s.repo.ListPipelineRecordsPage(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for i := range records {
+			record := e.s.repo.GetPipelineRecord(ctx, records[i].ID)
+			if record == nil {
+				continue
+			}
+			text, err := readPipelineLog(record)
+			if err != nil {
+				text = fmt.Sprintf("OpsGo: %v\n", err)
+			} else if e.timestamps {
+				text = timestampLog(text, record.LogMarks)
+			}
+
+			modTime := record.CreatedAt
+			if record.FinishedAt != nil {
+				modTime = *record.FinishedAt
+			}
+			if err := writeTarFile(tw, fmt.Sprintf("%s/%d.log", e.dir, record.ID), []byte(text), modTime); err != nil {
+				return err
+			}
+
+			pipelines = append(pipelines, toPipelineResponse(record))
+		}
+		if len(records) < filter.Limit {
+			break
+		}
+		filter.Offset += filter.Limit
+	}
+
+	manifest, err := json.MarshalIndent(pipelines, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, e.dir+"/pipelines.json", manifest, time.Now()); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
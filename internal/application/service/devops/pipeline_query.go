@@ -0,0 +1,69 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ListPipelines returns one page of pipeline history matching req.
+func (s *DevOpsService) ListPipelines(ctx context.Context, req dto.PipelineListRequest) ([]dto.PipelineRecordResponse, int64, error) {
+	filter := repository.PipelineFilter{
+		ConfigID:      req.ServiceID,
+		TriggerSource: req.TriggerSource,
+		Ref:           req.Ref,
+		Author:        req.Author,
+		SortBy:        req.Sort,
+		SortDesc:      req.Order == "desc",
+		Offset:        req.GetOffset(),
+		Limit:         req.GetPageSize(),
+	}
+	if req.Status != "" {
+		filter.Statuses = strings.Split(req.Status, ",")
+	}
+
+	var err error
+	if filter.From, err = parseDateParam(req.From, false); err != nil {
+		return nil, 0, err
+	}
+	if filter.To, err = parseDateParam(req.To, true); err != nil {
+		return nil, 0, err
+	}
+
+	records, total, err := s.repo.ListPipelineRecordsPage(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pipelines := make([]dto.PipelineRecordResponse, 0, len(records))
+	for i := range records {
+		pipelines = append(pipelines, toPipelineResponse(&records[i]))
+	}
+	return pipelines, total, nil
+}
+
+// ErrInvalidDate is returned for date parameters parseDateParam can't parse.
+var ErrInvalidDate = errors.New("invalid date")
+
+// parseDateParam accepts RFC3339 or a bare date in server local time. A bare
+// end date includes the whole day.
+func parseDateParam(value string, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q, expected RFC3339 or %s", ErrInvalidDate, value, time.DateOnly)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
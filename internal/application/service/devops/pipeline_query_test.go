@@ -0,0 +1,55 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPipelineLogOnlyInDetail(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	repoConfig := &devops.RepoConfig{Name: "app"}
+	if err := s.repo.SaveConfig(ctx, repoConfig); err != nil {
+		t.Fatal(err)
+	}
+	record := &devops.PipelineRecord{ConfigID: repoConfig.ID, RepoName: "app", Status: "success", Log: "deployed\n"}
+	if err := s.repo.CreatePipelineRecord(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := s.GetSummary(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Pipelines) != 1 || summary.Pipelines[0].Log != "" {
+		t.Errorf("summary pipelines %+v, want one without log", summary.Pipelines)
+	}
+
+	page, _, err := s.ListPipelines(ctx, dto.PipelineListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].Log != "" {
+		t.Errorf("pipeline page %+v, want one without log", page)
+	}
+
+	detail, err := s.GetPipelineDetail(ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Pipeline.Log != "deployed\n" {
+		t.Errorf("detail log %q", detail.Pipeline.Log)
+	}
+}
+
+func TestListPipelinesInvalidDate(t *testing.T) {
+	s := newTestService(t)
+	for _, req := range []dto.PipelineListRequest{{From: "yesterday"}, {To: "2024-13-01"}} {
+		if _, _, err := s.ListPipelines(context.Background(), req); !errors.Is(err, ErrInvalidDate) {
+			t.Errorf("ListPipelines(from %q, to %q) = %v, want ErrInvalidDate", req.From, req.To, err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package devops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// redisSeqTTL is how long the sequence counter of a pipeline is kept after
+// its last event.
+const redisSeqTTL = 24 * time.Hour
+
+// RedisBroadcaster shares events between OpsGo instances over Redis Pub/Sub.
+// Every instance, including the sender, receives events from the channel and
+// fans them out to its own clients, so all clients see the same order.
+// Sequence numbers come from a counter per pipeline in Redis, as the events of
+// a pipeline can be sent by several instances, e.g. when it is approved on
+// another instance than the one it was triggered on.
+type RedisBroadcaster struct {
+	client  *goredis.Client
+	channel string
+	local   *LogBroadcaster
+	queue   *eventQueue
+	seq     sequencer
+}
+
+func NewRedisBroadcaster(client *goredis.Client, channel string) *RedisBroadcaster {
+	rb := &RedisBroadcaster{
+		client:  client,
+		channel: channel,
+		local:   NewLogBroadcaster(),
+		queue:   newEventQueue(),
+		seq:     sequencer{next: make(map[uint64]uint64)},
+	}
+	go rb.publishLoop()
+	go rb.subscribeLoop()
+	return rb
+}
+
+// publishLoop is the only publisher, so events leave this instance in the
+// order they were broadcast.
+func (rb *RedisBroadcaster) publishLoop() {
+	ctx := context.Background()
+	for range rb.queue.ready {
+		for _, event := range rb.queue.drain() {
+			rb.stamp(ctx, &event)
+			payload, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if err := rb.client.Publish(ctx, rb.channel, payload).Err(); err != nil {
+				// Other instances miss the event, but local clients still get it.
+				log.Printf("Failed to publish pipeline event: %v", err)
+				rb.local.relay(event)
+			}
+		}
+	}
+}
+
+// subscribeLoop relays the channel to local clients; go-redis reconnects and
+// resubscribes on connection errors.
+func (rb *RedisBroadcaster) subscribeLoop() {
+	sub := rb.client.Subscribe(context.Background(), rb.channel)
+	defer sub.Close()
+
+	for msg := range sub.Channel() {
+		var event LogEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			log.Printf("Invalid pipeline event on %s: %v", rb.channel, err)
+			continue
+		}
+		rb.local.relay(event)
+	}
+}
+
+// stamp numbers an event with the counter of its pipeline in Redis, falling
+// back to counting on from the last number seen here while Redis fails.
+func (rb *RedisBroadcaster) stamp(ctx context.Context, event *LogEvent) {
+	key := fmt.Sprintf("%s:seq:%d", rb.channel, event.PipelineID)
+	var incr *goredis.IntCmd
+	_, err := rb.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, redisSeqTTL)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to number pipeline event: %v", err)
+		rb.seq.stamp(event)
+		return
+	}
+	event.Seq = uint64(incr.Val())
+	rb.seq.observe(event)
+}
+
+func (rb *RedisBroadcaster) Register() chan LogEvent {
+	return rb.local.Register()
+}
+
+func (rb *RedisBroadcaster) Unregister(client chan LogEvent) {
+	rb.local.Unregister(client)
+}
+
+// Broadcast queues the event for numbering and publishing without waiting
+// for Redis.
+func (rb *RedisBroadcaster) Broadcast(event LogEvent) {
+	rb.queue.push(event)
+}
+
+// Replay serves the events this instance received from the channel.
+func (rb *RedisBroadcaster) Replay(pipelineID, seq uint64) []LogEvent {
+	return rb.local.Replay(pipelineID, seq)
+}
+
+func (rb *RedisBroadcaster) BroadcastLog(pipelineID uint64, content string) {
+	rb.Broadcast(LogEvent{
+		Type:       "log",
+		PipelineID: pipelineID,
+		Content:    content,
+	})
+}
+
+func (rb *RedisBroadcaster) BroadcastStatus(pipelineID uint64, status string) {
+	rb.Broadcast(LogEvent{
+		Type:       "status",
+		PipelineID: pipelineID,
+		Status:     status,
+	})
+}
@@ -0,0 +1,53 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+func (s *DevOpsService) ListReleases(ctx context.Context, configID uint64) ([]devops.Release, error) {
+	if s.repo.GetConfig(ctx, configID) == nil {
+		return nil, fmt.Errorf("config not found")
+	}
+	return s.repo.ListReleases(ctx, configID)
+}
+
+// GetCurrentRelease returns the release currently deployed for the service.
+func (s *DevOpsService) GetCurrentRelease(ctx context.Context, configID uint64) (*devops.Release, error) {
+	if s.repo.GetConfig(ctx, configID) == nil {
+		return nil, fmt.Errorf("config not found")
+	}
+	release := s.repo.GetActiveRelease(ctx, configID)
+	if release == nil {
+		return nil, fmt.Errorf("no release deployed")
+	}
+	return release, nil
+}
+
+// recordRelease makes the successful pipeline the active release of its service.
+func (s *DevOpsService) recordRelease(ctx context.Context, record *devops.PipelineRecord, deployedAt time.Time) {
+	release := &devops.Release{
+		ConfigID:    record.ConfigID,
+		ServiceName: record.RepoName,
+		Version:     releaseVersion(record),
+		CommitSHA:   record.CommitSHA,
+		PipelineID:  record.ID,
+		DeployedAt:  deployedAt,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.ActivateRelease(ctx, release); err != nil {
+		log.Printf("Failed to record release of pipeline %d: %v", record.ID, err)
+	}
+}
+
+// releaseVersion prefers the ref; manual deploys without one are versioned by
+// their script argument.
+func releaseVersion(record *devops.PipelineRecord) string {
+	if record.Ref != "" && record.Ref != "manual" {
+		return record.Ref
+	}
+	return pipelineArgs(record)[0]
+}
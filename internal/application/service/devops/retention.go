@@ -0,0 +1,287 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/domain/repository"
+	"OpsGo/internal/infrastructure/config"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	retentionBatchSize = 100
+	retentionRunsLimit = 50
+)
+
+// ErrRetentionRunning is returned when a retention run is already in progress.
+var ErrRetentionRunning = errors.New("retention is already running")
+
+// StartRetention periodically applies the retention policy of pipeline
+// records and logs, see RetentionConfig.
+func (s *DevOpsService) StartRetention() {
+	cfg := config.AppConfig.Retention
+	if !cfg.Enabled {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(cfg.Interval) * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if !s.claimJob("retention", time.Duration(cfg.Interval)*time.Minute*9/10) {
+					continue
+				}
+				if _, err := s.RunRetention(context.Background(), cfg.DryRun); err != nil {
+					log.Printf("Retention run failed: %v", err)
+				}
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+	log.Println("Retention job started")
+}
+
+// RunRetention deletes the pipelines older than max_age_days and moves the
+// logs of the ones past the keep_last most recent of each service to gzip
+// archives. A dry run only reports what would be done.
+func (s *DevOpsService) RunRetention(ctx context.Context, dryRun bool) (*devops.RetentionRun, error) {
+	if !s.retentionMu.TryLock() {
+		return nil, ErrRetentionRunning
+	}
+	defer s.retentionMu.Unlock()
+
+	cfg := config.AppConfig.Retention
+	run := &devops.RetentionRun{DryRun: dryRun, StartedAt: time.Now()}
+	fail := func(format string, args ...interface{}) {
+		run.Errors++
+		run.LastError = fmt.Sprintf(format, args...)
+		log.Printf("Retention: %s", run.LastError)
+	}
+
+	// Purged first, so that their logs aren't archived for nothing.
+	purged := make(map[uint64]bool)
+	if cfg.MaxAgeDays > 0 {
+		before := time.Now().AddDate(0, 0, -cfg.MaxAgeDays)
+		err := s.eachRetentionCandidate(ctx, repository.RetentionFilter{
+			KeepLast:      cfg.KeepLast,
+			Statuses:      finalStatuses,
+			CreatedBefore: &before,
+			KeepActive:    true,
+		}, func(c repository.RetentionCandidate) {
+			size := c.LogSize
+			if c.LogArchive != "" {
+				if info, err := os.Stat(archivePath(c.LogArchive)); err == nil {
+					size += info.Size()
+				}
+			}
+			if !dryRun {
+				if err := s.purgePipeline(ctx, c); err != nil {
+					fail("failed to purge pipeline %d: %v", c.ID, err)
+					return
+				}
+			}
+			purged[c.ID] = true
+			run.Purged++
+			run.PurgedBytes += size
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.KeepLast > 0 {
+		err := s.eachRetentionCandidate(ctx, repository.RetentionFilter{
+			KeepLast:  cfg.KeepLast,
+			Statuses:  finalStatuses,
+			InlineLog: true,
+		}, func(c repository.RetentionCandidate) {
+			if purged[c.ID] {
+				return
+			}
+			var size int64
+			var err error
+			if dryRun {
+				size, err = s.estimateArchiveSize(ctx, c)
+			} else {
+				size, err = s.archivePipelineLog(ctx, c)
+			}
+			if err != nil {
+				fail("failed to archive log of pipeline %d: %v", c.ID, err)
+				return
+			}
+			run.ArchiveBytes += size
+			run.Archived++
+			run.ArchivedLogBytes += c.OwnLogSize
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	run.ReclaimedBytes = run.ArchivedLogBytes - run.ArchiveBytes + run.PurgedBytes
+
+	// SQLite only reuses the pages freed above; VACUUM gives them back.
+	if cfg.Vacuum && !dryRun && run.Archived+run.Purged > 0 {
+		if err := s.repo.Vacuum(ctx); err != nil {
+			fail("failed to vacuum the database: %v", err)
+		} else {
+			run.Vacuumed = true
+		}
+	}
+	run.FinishedAt = time.Now()
+	if err := s.repo.CreateRetentionRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (s *DevOpsService) ListRetentionRuns(ctx context.Context) ([]devops.RetentionRun, error) {
+	return s.repo.ListRetentionRuns(ctx, retentionRunsLimit)
+}
+
+func (s *DevOpsService) eachRetentionCandidate(ctx context.Context, filter repository.RetentionFilter, fn func(repository.RetentionCandidate)) error {
+	filter.Limit = retentionBatchSize
+	for {
+		candidates, err := s.repo.ListRetentionCandidates(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, c := range candidates {
+			fn(c)
+		}
+		if len(candidates) < retentionBatchSize {
+			return nil
+		}
+		filter.AfterID = candidates[len(candidates)-1].ID
+	}
+}
+
+// archivePipelineLog writes the log of a pipeline to a gzip file and removes
+// it from the database. It returns the size of the file.
+func (s *DevOpsService) archivePipelineLog(ctx context.Context, c repository.RetentionCandidate) (int64, error) {
+	record := s.repo.GetPipelineRecord(ctx, c.ID)
+	if record == nil {
+		return 0, ErrPipelineNotFound
+	}
+
+	rel := filepath.Join("logs", fmt.Sprint(record.ConfigID), fmt.Sprintf("%d.log.gz", record.ID))
+	path := archivePath(rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".archive-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	_, err = io.WriteString(zw, record.Log)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+
+	if err := s.repo.ArchivePipelineLog(ctx, record.ID, filepath.ToSlash(rel)); err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// estimateArchiveSize returns the size archivePipelineLog would write.
+func (s *DevOpsService) estimateArchiveSize(ctx context.Context, c repository.RetentionCandidate) (int64, error) {
+	record := s.repo.GetPipelineRecord(ctx, c.ID)
+	if record == nil {
+		return 0, ErrPipelineNotFound
+	}
+	var n byteCounter
+	zw := gzip.NewWriter(&n)
+	if _, err := io.WriteString(zw, record.Log); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	return int64(n), nil
+}
+
+// byteCounter is a writer counting the bytes written to it.
+type byteCounter int64
+
+func (n *byteCounter) Write(p []byte) (int, error) {
+	*n += byteCounter(len(p))
+	return len(p), nil
+}
+
+func (s *DevOpsService) purgePipeline(ctx context.Context, c repository.RetentionCandidate) error {
+	artifacts, err := s.repo.ListArtifacts(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	for _, a := range artifacts {
+		if err := s.artifacts.Delete(a.Path); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.DeletePipelineRecord(ctx, c.ID); err != nil {
+		return err
+	}
+	if c.LogArchive != "" {
+		if err := os.Remove(archivePath(c.LogArchive)); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to delete log archive %s: %v", c.LogArchive, err)
+		}
+	}
+	return nil
+}
+
+func archivePath(rel string) string {
+	return filepath.Join(config.AppConfig.Retention.ArchiveDir, filepath.FromSlash(rel))
+}
+
+// readPipelineLog returns the log of a pipeline, from its archive once the
+// retention policy moved it out of the database.
+func readPipelineLog(record *devops.PipelineRecord) (string, error) {
+	if record.LogArchive == "" {
+		return record.Log, nil
+	}
+	f, err := os.Open(archivePath(record.LogArchive))
+	if err != nil {
+		return "", fmt.Errorf("failed to open log archive: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to read log archive: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read log archive: %v", err)
+	}
+	return string(data), nil
+}
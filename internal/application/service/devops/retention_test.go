@@ -0,0 +1,100 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/infrastructure/config"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunRetention(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	config.AppConfig.Retention.KeepLast = 1
+	config.AppConfig.Retention.MaxAgeDays = 30
+	config.AppConfig.Retention.Vacuum = true
+
+	repoConfig := &devops.RepoConfig{Name: "app"}
+	if err := s.repo.SaveConfig(ctx, repoConfig); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	records := make([]*devops.PipelineRecord, 3)
+	for i, createdAt := range []time.Time{now.AddDate(0, 0, -60), now.AddDate(0, 0, -1), now} {
+		records[i] = &devops.PipelineRecord{
+			ConfigID:  repoConfig.ID,
+			RepoName:  "app",
+			Status:    "success",
+			Log:       strings.Repeat("step ok\n", 1000),
+			CreatedAt: createdAt,
+		}
+		if err := s.repo.CreatePipelineRecord(ctx, records[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	attempt := &devops.PipelineAttempt{PipelineID: records[1].ID, Attempt: 1, Log: "attempt ok\n"}
+	if err := s.repo.CreatePipelineAttempt(ctx, attempt); err != nil {
+		t.Fatal(err)
+	}
+	// The release of the oldest pipeline is retired by the one of the newest.
+	for _, i := range []int{0, 2} {
+		release := &devops.Release{ConfigID: repoConfig.ID, ServiceName: "app", PipelineID: records[i].ID, DeployedAt: records[i].CreatedAt}
+		if err := s.repo.ActivateRelease(ctx, release); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dry, err := s.RunRetention(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dry.Purged != 1 || dry.Archived != 1 || dry.Vacuumed {
+		t.Errorf("dry run purged %d, archived %d, vacuumed %v, want 1, 1, false", dry.Purged, dry.Archived, dry.Vacuumed)
+	}
+	if dry.ArchivedLogBytes != int64(len(records[1].Log)) {
+		t.Errorf("dry run archived log bytes %d, want %d", dry.ArchivedLogBytes, len(records[1].Log))
+	}
+	if dry.ArchiveBytes <= 0 || dry.ArchiveBytes >= dry.ArchivedLogBytes {
+		t.Errorf("dry run archive bytes %d for %d log bytes", dry.ArchiveBytes, dry.ArchivedLogBytes)
+	}
+
+	run, err := s.RunRetention(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Purged != 1 || run.Archived != 1 || run.Errors != 0 || !run.Vacuumed {
+		t.Errorf("run purged %d, archived %d, errors %d (%s), vacuumed %v", run.Purged, run.Archived, run.Errors, run.LastError, run.Vacuumed)
+	}
+	if run.ArchiveBytes != dry.ArchiveBytes || run.ReclaimedBytes != dry.ReclaimedBytes {
+		t.Errorf("run archive bytes %d, reclaimed %d, dry run estimated %d, %d",
+			run.ArchiveBytes, run.ReclaimedBytes, dry.ArchiveBytes, dry.ReclaimedBytes)
+	}
+
+	if s.repo.GetPipelineRecord(ctx, records[0].ID) != nil {
+		t.Errorf("pipeline %d not purged", records[0].ID)
+	}
+	archived := s.repo.GetPipelineRecord(ctx, records[1].ID)
+	if archived.Log != "" || archived.LogArchive == "" {
+		t.Errorf("pipeline %d log %d bytes inline, archive %q", archived.ID, len(archived.Log), archived.LogArchive)
+	}
+	attempts, err := s.repo.ListPipelineAttempts(ctx, records[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 1 || attempts[0].Log != attempt.Log {
+		t.Errorf("attempts of the archived pipeline %+v, want their log kept", attempts)
+	}
+	if text, err := readPipelineLog(archived); err != nil || text != records[1].Log {
+		t.Errorf("archived log %d bytes, %v", len(text), err)
+	}
+
+	releases, err := s.repo.ListReleases(ctx, repoConfig.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 1 || releases[0].PipelineID != records[2].ID || !releases[0].Active {
+		t.Errorf("releases %+v, want only the active one of pipeline %d", releases, records[2].ID)
+	}
+}
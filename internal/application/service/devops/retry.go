@@ -0,0 +1,30 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"slices"
+	"time"
+)
+
+const maxRetryBackoff = 10 * time.Minute
+
+// sleep waits between delivery attempts of notifications and commit
+// statuses; tests replace it.
+var sleep = time.Sleep
+
+// isRetryable reports whether a failed attempt with exitCode may be re-run.
+func isRetryable(policy devops.RetryPolicy, exitCode int) bool {
+	if len(policy.ExitCodes) == 0 {
+		return true
+	}
+	return slices.Contains(policy.ExitCodes, exitCode)
+}
+
+// retryBackoff returns the delay after the given attempt, doubling each time.
+func retryBackoff(policy devops.RetryPolicy, attempt int) time.Duration {
+	delay := time.Duration(policy.Backoff) * time.Second
+	for i := 1; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
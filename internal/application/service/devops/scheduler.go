@@ -0,0 +1,225 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const schedulerInterval = 30 * time.Second
+
+func (s *DevOpsService) CreateSchedule(ctx context.Context, req dto.ScheduleRequest, user string) (*devops.DeploySchedule, error) {
+	if s.repo.GetConfig(ctx, req.ConfigID) == nil {
+		return nil, fmt.Errorf("config not found")
+	}
+	if err := validatePipelineEnv(req.Env); err != nil {
+		return nil, err
+	}
+	loc, err := loadLocation(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &devops.DeploySchedule{
+		ConfigID:  req.ConfigID,
+		Name:      req.Name,
+		Cron:      req.Cron,
+		Timezone:  req.Timezone,
+		Ref:       req.Ref,
+		Env:       req.Env,
+		CreatedBy: user,
+	}
+
+	switch {
+	case req.Cron != "" && req.RunAt != "":
+		return nil, fmt.Errorf("cron and run_at are mutually exclusive")
+	case req.Cron != "":
+		if _, err := cron.ParseStandard(req.Cron); err != nil {
+			return nil, fmt.Errorf("invalid cron expression: %v", err)
+		}
+	case req.RunAt != "":
+		if schedule.RunAt, err = parseTimeIn(req.RunAt, loc); err != nil {
+			return nil, err
+		}
+		if schedule.RunAt.Before(time.Now()) {
+			return nil, fmt.Errorf("run_at is in the past")
+		}
+	default:
+		return nil, fmt.Errorf("either cron or run_at is required")
+	}
+
+	if schedule.NextRunAt, err = nextRun(schedule, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *DevOpsService) ListSchedules(ctx context.Context) ([]devops.DeploySchedule, error) {
+	return s.repo.ListSchedules(ctx)
+}
+
+// PauseSchedule stops a schedule from firing until it is resumed.
+func (s *DevOpsService) PauseSchedule(ctx context.Context, id uint64) (*devops.DeploySchedule, error) {
+	schedule := s.repo.GetSchedule(ctx, id)
+	if schedule == nil {
+		return nil, fmt.Errorf("schedule not found")
+	}
+
+	schedule.Paused = true
+	if err := s.repo.SaveSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// ResumeSchedule re-enables a schedule. Runs missed while paused are skipped,
+// so a one-shot schedule whose time passed never fires.
+func (s *DevOpsService) ResumeSchedule(ctx context.Context, id uint64) (*devops.DeploySchedule, error) {
+	schedule := s.repo.GetSchedule(ctx, id)
+	if schedule == nil {
+		return nil, fmt.Errorf("schedule not found")
+	}
+
+	next, err := nextRun(schedule, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	schedule.Paused = false
+	schedule.NextRunAt = next
+	if err := s.repo.SaveSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *DevOpsService) DeleteSchedule(ctx context.Context, id uint64) error {
+	if s.repo.GetSchedule(ctx, id) == nil {
+		return fmt.Errorf("schedule not found")
+	}
+	return s.repo.DeleteSchedule(ctx, id)
+}
+
+// StartScheduler fires due schedules. Next run times are persisted, so a run
+// missed while OpsGo was down fires once right after the restart.
+func (s *DevOpsService) StartScheduler() {
+	go func() {
+		s.runDueSchedules()
+
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.runDueSchedules()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+	log.Println("Deploy scheduler started")
+}
+
+func (s *DevOpsService) runDueSchedules() {
+	ctx := context.Background()
+	now := time.Now()
+	schedules, err := s.repo.ListDueSchedules(ctx, now.UTC())
+	if err != nil {
+		log.Printf("Failed to list due schedules: %v", err)
+		return
+	}
+
+	for i := range schedules {
+		schedule := &schedules[i]
+		next, nextErr := nextRun(schedule, now)
+
+		// Every instance runs the scheduler; the one moving the next run
+		// time on starts the deployment.
+		claimed, err := s.repo.ClaimScheduleRun(ctx, schedule.ID, *schedule.NextRunAt, next)
+		if err != nil {
+			log.Printf("Failed to claim run of schedule %d: %v", schedule.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		schedule.NextRunAt = next
+
+		record, err := s.runSchedule(ctx, schedule)
+		schedule.LastRunAt = &now
+		schedule.LastError = ""
+		if err != nil {
+			schedule.LastError = err.Error()
+			log.Printf("Schedule %d failed to start a deployment: %v", schedule.ID, err)
+		}
+		if record != nil {
+			schedule.LastPipelineID = record.ID
+		}
+		if nextErr != nil {
+			schedule.LastError = nextErr.Error()
+		}
+		if err := s.repo.SaveScheduleRun(ctx, schedule); err != nil {
+			log.Printf("Failed to update schedule %d: %v", schedule.ID, err)
+		}
+	}
+}
+
+func (s *DevOpsService) runSchedule(ctx context.Context, schedule *devops.DeploySchedule) (*devops.PipelineRecord, error) {
+	config := s.repo.GetConfig(ctx, schedule.ConfigID)
+	if config == nil {
+		return nil, fmt.Errorf("config not found")
+	}
+
+	ref := schedule.Ref
+	if ref == "" {
+		ref = "latest"
+	}
+	record := &devops.PipelineRecord{
+		ConfigID:      config.ID,
+		RepoName:      config.Name,
+		Status:        "pending",
+		Ref:           ref,
+		Args:          []string{ref},
+		Env:           schedule.Env,
+		TriggerSource: "schedule",
+		TriggeredBy:   "scheduler",
+		ScheduleID:    schedule.ID,
+		CreatedAt:     time.Now(),
+	}
+
+	if err := s.admitPipeline(ctx, config, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// nextRun returns the first run strictly after now, or nil when a one-shot
+// schedule has nothing left to run.
+func nextRun(schedule *devops.DeploySchedule, now time.Time) (*time.Time, error) {
+	if schedule.Cron == "" {
+		if schedule.RunAt == nil || !schedule.RunAt.After(now) {
+			return nil, nil
+		}
+		next := schedule.RunAt.UTC()
+		return &next, nil
+	}
+
+	sched, err := cron.ParseStandard(schedule.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression: %v", err)
+	}
+	loc, err := loadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	next := sched.Next(now.In(loc)).UTC()
+	return &next, nil
+}
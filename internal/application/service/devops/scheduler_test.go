@@ -0,0 +1,140 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRunDueSchedulesClaimsRun(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	script := filepath.Join(t.TempDir(), "deploy.sh")
+	if err := os.WriteFile(script, []byte("exit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repoConfig := &devops.RepoConfig{Name: "app", RepoURL: "https://example.com/acme/app.git", DeployScript: script}
+	if err := s.repo.SaveConfig(ctx, repoConfig); err != nil {
+		t.Fatal(err)
+	}
+	due := time.Now().Add(-time.Minute).UTC()
+	schedule := &devops.DeploySchedule{ConfigID: repoConfig.ID, Cron: "0 2 * * *", NextRunAt: &due}
+	if err := s.repo.SaveSchedule(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	// What another instance listed before this one ran the schedule.
+	stale, err := s.repo.ListDueSchedules(ctx, time.Now().UTC())
+	if err != nil || len(stale) != 1 {
+		t.Fatalf("ListDueSchedules = %d schedules, %v", len(stale), err)
+	}
+
+	s.runDueSchedules()
+
+	got := s.repo.GetSchedule(ctx, schedule.ID)
+	if got.LastPipelineID == 0 || got.NextRunAt == nil || !got.NextRunAt.After(time.Now()) {
+		t.Fatalf("schedule after run: last pipeline %d, next run %v", got.LastPipelineID, got.NextRunAt)
+	}
+	claimed, err := s.repo.ClaimScheduleRun(ctx, stale[0].ID, *stale[0].NextRunAt, got.NextRunAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed {
+		t.Error("run already claimed was claimed again")
+	}
+
+	// Let the pipeline finish before the database is closed.
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		record := s.repo.GetPipelineRecord(ctx, got.LastPipelineID)
+		if record != nil && slices.Contains(finalStatuses, record.Status) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pipeline %d did not finish", got.LastPipelineID)
+		}
+	}
+}
+
+func TestResumeSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule devops.DeploySchedule
+		wantNext bool
+	}{
+		{name: "cron", schedule: devops.DeploySchedule{Cron: "0 2 * * *"}, wantNext: true},
+		{name: "one-shot ahead", schedule: devops.DeploySchedule{RunAt: ptrTime(time.Now().Add(time.Hour))}, wantNext: true},
+		{name: "one-shot passed", schedule: devops.DeploySchedule{RunAt: ptrTime(time.Now().Add(-time.Hour))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			ctx := context.Background()
+
+			repoConfig := &devops.RepoConfig{Name: "app"}
+			if err := s.repo.SaveConfig(ctx, repoConfig); err != nil {
+				t.Fatal(err)
+			}
+			// Paused when NextRunAt was still ahead.
+			schedule := tt.schedule
+			schedule.ConfigID = repoConfig.ID
+			schedule.Paused = true
+			schedule.NextRunAt = ptrTime(time.Now().Add(-time.Minute).UTC())
+			if err := s.repo.SaveSchedule(ctx, &schedule); err != nil {
+				t.Fatal(err)
+			}
+
+			resumed, err := s.ResumeSchedule(ctx, schedule.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resumed.Paused {
+				t.Error("schedule still paused")
+			}
+			if got := resumed.NextRunAt != nil; got != tt.wantNext {
+				t.Fatalf("next run %v, want one: %v", resumed.NextRunAt, tt.wantNext)
+			}
+			if tt.wantNext && !resumed.NextRunAt.After(time.Now()) {
+				t.Errorf("next run %v is not ahead", resumed.NextRunAt)
+			}
+
+			due, err := s.repo.ListDueSchedules(ctx, time.Now().UTC())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(due) != 0 {
+				t.Errorf("resumed schedule is due: %+v", due[0])
+			}
+		})
+	}
+}
+
+func TestCreateScheduleEnv(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	repoConfig := &devops.RepoConfig{Name: "app"}
+	if err := s.repo.SaveConfig(ctx, repoConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	req := dto.ScheduleRequest{ConfigID: repoConfig.ID, Cron: "0 2 * * *", Env: map[string]string{"OPSGO_VAR_REGION": "eu"}}
+	if schedule, err := s.CreateSchedule(ctx, req, "alice"); err != nil {
+		t.Errorf("CreateSchedule() with OPSGO_VAR_ env: %v", err)
+	} else if schedule.CreatedBy != "alice" {
+		t.Errorf("schedule created by %q, want alice", schedule.CreatedBy)
+	}
+	req.Env = map[string]string{"LD_PRELOAD": "/tmp/evil.so"}
+	if _, err := s.CreateSchedule(ctx, req, "alice"); !errors.Is(err, ErrInvalidEnv) {
+		t.Errorf("CreateSchedule() with LD_PRELOAD = %v, want ErrInvalidEnv", err)
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,210 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/domain/repository"
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	logIndexInterval  = time.Minute
+	logIndexBatchSize = 100
+	logIndexQueueSize = 256
+	logSearchHits     = 20 // matching lines returned per pipeline
+)
+
+var snippetMarks = strings.NewReplacer(repository.MatchStart, "<mark>", repository.MatchEnd, "</mark>")
+
+// LogSearchService indexes the logs of finished pipelines and searches them.
+type LogSearchService struct {
+	repo     repository.DevOpsRepository
+	queue    chan uint64
+	stopChan chan struct{}
+}
+
+func NewLogSearchService(repo repository.DevOpsRepository) *LogSearchService {
+	return &LogSearchService{
+		repo:     repo,
+		queue:    make(chan uint64, logIndexQueueSize),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// IndexPipeline queues the log of a finished pipeline for indexing. Pipelines
+// that don't fit in the queue are picked up by the next backfill.
+func (s *LogSearchService) IndexPipeline(record *devops.PipelineRecord) {
+	if !slices.Contains(finalStatuses, record.Status) {
+		return
+	}
+	select {
+	case s.queue <- record.ID:
+	default:
+	}
+}
+
+// StartIndexer indexes queued pipelines and periodically backfills the logs
+// of finished pipelines missing from the index, such as the ones that
+// finished before the index existed.
+func (s *LogSearchService) StartIndexer() {
+	go func() {
+		ticker := time.NewTicker(logIndexInterval)
+		defer ticker.Stop()
+
+		s.backfill()
+		for {
+			select {
+			case id := <-s.queue:
+				s.index(context.Background(), id)
+			case <-ticker.C:
+				s.backfill()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+	log.Println("Log indexer started")
+}
+
+func (s *LogSearchService) Stop() {
+	close(s.stopChan)
+}
+
+func (s *LogSearchService) backfill() {
+	ctx := context.Background()
+	for {
+		ids, err := s.repo.ListUnindexedPipelineIDs(ctx, finalStatuses, logIndexBatchSize)
+		if err != nil {
+			log.Printf("Failed to list unindexed pipelines: %v", err)
+			return
+		}
+		for _, id := range ids {
+			s.index(ctx, id)
+		}
+		if len(ids) < logIndexBatchSize {
+			return
+		}
+		select {
+		case <-s.stopChan:
+			return
+		default:
+		}
+	}
+}
+
+func (s *LogSearchService) index(ctx context.Context, pipelineID uint64) {
+	record := s.repo.GetPipelineRecord(ctx, pipelineID)
+	if record == nil {
+		return
+	}
+	text, err := readPipelineLog(record)
+	skipped := 0
+	if err == nil {
+		skipped, err = s.repo.IndexPipelineLog(ctx, record.ID, text)
+	}
+	if err != nil {
+		log.Printf("Failed to index log of pipeline %d: %v", record.ID, err)
+	} else if skipped > 0 {
+		log.Printf("Log of pipeline %d is too long to index, %d line(s) left out", record.ID, skipped)
+	}
+}
+
+// Search returns one page of the pipelines whose logs have a line matching
+// the query, with the first matching lines of each.
+func (s *LogSearchService) Search(ctx context.Context, req dto.LogSearchRequest) ([]dto.LogSearchResult, int64, error) {
+	query, err := matchQuery(req.Q)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter := repository.LogSearchFilter{
+		Query:    query,
+		ConfigID: req.ServiceID,
+		SortDesc: req.Order == "desc",
+		Offset:   req.GetOffset(),
+		Limit:    req.GetPageSize(),
+	}
+	if filter.From, err = parseDateParam(req.From, false); err != nil {
+		return nil, 0, err
+	}
+	if filter.To, err = parseDateParam(req.To, true); err != nil {
+		return nil, 0, err
+	}
+
+	matches, total, err := s.repo.SearchPipelineLogs(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]dto.LogSearchResult, 0, len(matches))
+	for _, m := range matches {
+		hits, err := s.repo.ListLogSearchHits(ctx, query, m.PipelineID, logSearchHits)
+		if err != nil {
+			return nil, 0, err
+		}
+		result := dto.LogSearchResult{
+			PipelineID: m.PipelineID,
+			ConfigID:   m.ConfigID,
+			RepoName:   m.RepoName,
+			Status:     m.Status,
+			Ref:        m.Ref,
+			CommitSHA:  m.CommitSHA,
+			CreatedAt:  m.CreatedAt,
+			Matches:    m.Matches,
+			Hits:       make([]dto.LogSearchHit, 0, len(hits)),
+		}
+		for _, hit := range hits {
+			result.Hits = append(result.Hits, dto.LogSearchHit{
+				Line:    hit.Line,
+				Snippet: snippetMarks.Replace(html.EscapeString(hit.Snippet)),
+			})
+		}
+		results = append(results, result)
+	}
+	return results, total, nil
+}
+
+// matchQuery turns a search into an FTS5 expression matching lines with all
+// of its terms. Double quoted text is a phrase and a trailing * makes a term
+// a prefix; other FTS5 syntax is searched literally.
+func matchQuery(q string) (string, error) {
+	var terms []string
+	for q = strings.TrimSpace(q); q != ""; q = strings.TrimSpace(q) {
+		var term string
+		prefix := false
+		if q[0] == '"' {
+			end := strings.IndexByte(q[1:], '"')
+			if end < 0 {
+				term, q = q[1:], ""
+			} else {
+				term, q = q[1:end+1], q[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(q, unicode.IsSpace)
+			if end < 0 {
+				end = len(q)
+			}
+			term, q = q[:end], q[end:]
+			term, prefix = strings.CutSuffix(term, "*")
+		}
+		if strings.TrimSpace(term) == "" {
+			continue
+		}
+
+		expr := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			expr += "*"
+		}
+		terms = append(terms, expr)
+	}
+	if len(terms) == 0 {
+		return "", fmt.Errorf("search query is empty")
+	}
+	return strings.Join(terms, " "), nil
+}
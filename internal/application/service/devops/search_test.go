@@ -0,0 +1,69 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"context"
+	"testing"
+)
+
+func TestMatchQuery(t *testing.T) {
+	tests := []struct {
+		q, want string
+	}{
+		{"timeout", `"timeout"`},
+		{"  connection   refused ", `"connection" "refused"`},
+		{`"exit code 1" npm`, `"exit code 1" "npm"`},
+		{"deploy*", `"deploy"*`},
+		{`"unterminated phrase`, `"unterminated phrase"`},
+		{`a"b OR NEAR(c)`, `"a""b" "OR" "NEAR(c)"`},
+		{`"" *`, ""},
+	}
+	for _, tt := range tests {
+		got, err := matchQuery(tt.q)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("matchQuery(%q) = %q, want an error", tt.q, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("matchQuery(%q) = %q, %v, want %q", tt.q, got, err, tt.want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	search := NewLogSearchService(s.repo)
+
+	logs := []string{
+		"npm install\nError: <timeout> connecting\n",
+		"all good\n",
+		"retrying\nERROR timeout again\ntimeout\n",
+	}
+	for _, text := range logs {
+		record := &devops.PipelineRecord{RepoName: "app", Status: "failed", Log: text}
+		if err := s.repo.CreatePipelineRecord(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	search.backfill()
+
+	results, total, err := search.Search(ctx, dto.LogSearchRequest{Q: "error timeout", Order: "asc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(results) != 2 {
+		t.Fatalf("Search() = %d results (total %d), want 2", len(results), total)
+	}
+	first := results[0]
+	if first.Matches != 1 || len(first.Hits) != 1 || first.Hits[0].Line != 2 ||
+		first.Hits[0].Snippet != "<mark>Error</mark>: &lt;<mark>timeout</mark>&gt; connecting" {
+		t.Errorf("first result %+v", first)
+	}
+	if results[1].PipelineID <= first.PipelineID || results[1].Hits[0].Line != 2 {
+		t.Errorf("second result %+v", results[1])
+	}
+}
@@ -0,0 +1,260 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/infrastructure/config"
+	"OpsGo/internal/infrastructure/logfile"
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// defaultLogSource is the name under which RepoConfig.LogPath is served.
+const defaultLogSource = "default"
+
+// ListLogSources returns the runtime logs of a service.
+func (s *DevOpsService) ListLogSources(ctx context.Context, configID uint64) ([]devops.LogSource, error) {
+	repoConfig := s.repo.GetConfig(ctx, configID)
+	if repoConfig == nil {
+		return nil, fmt.Errorf("config not found")
+	}
+	return logSources(repoConfig), nil
+}
+
+// GetServiceLog returns one page of a runtime log of a service. Pages go back
+// in time, from the end of the log into its rotated files.
+func (s *DevOpsService) GetServiceLog(ctx context.Context, configID uint64, req dto.ServiceLogRequest) (*dto.ServiceLogResponse, error) {
+	source, err := s.logSource(ctx, configID, req.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter logfile.Filter
+	if req.Grep != "" {
+		if filter.Grep, err = regexp.Compile(req.Grep); err != nil {
+			return nil, fmt.Errorf("invalid grep pattern: %v", err)
+		}
+	}
+	if filter.Since, err = parseDateParam(req.Since, false); err != nil {
+		return nil, err
+	}
+	if filter.Until, err = parseDateParam(req.Until, true); err != nil {
+		return nil, err
+	}
+
+	var resp *dto.ServiceLogResponse
+	if source.Type == "journald" {
+		resp, err = readJournal(ctx, source, req, filter)
+	} else {
+		resp, err = readLogFiles(source, req, filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(resp.Lines)
+	return resp, nil
+}
+
+// readLogFiles collects the lines of a page newest first.
+func readLogFiles(source *devops.LogSource, req dto.ServiceLogRequest, filter logfile.Filter) (*dto.ServiceLogResponse, error) {
+	files, err := sourceFiles(source)
+	if err != nil {
+		return nil, err
+	}
+	resp := &dto.ServiceLogResponse{Lines: []dto.ServiceLogLine{}}
+	for _, path := range files {
+		resp.Files = append(resp.Files, filepath.Base(path))
+	}
+
+	skipped := 0
+	for i := req.File; i < len(files); i++ {
+		f, err := logfile.Open(files[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %v", err)
+		}
+		before := int64(-1)
+		if i == req.File && req.Before != nil {
+			before = *req.Before
+		}
+
+		full := false
+		older, err := logfile.Scan(f, before, filter, func(line logfile.Line) bool {
+			if skipped < req.Offset {
+				skipped++
+				return true
+			}
+			resp.Lines = append(resp.Lines, dto.ServiceLogLine{
+				File:   resp.Files[i],
+				Offset: line.Offset,
+				Time:   line.Time,
+				Text:   line.Text,
+			})
+			full = len(resp.Lines) == req.Lines
+			return !full
+		})
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read log file: %v", err)
+		}
+
+		if full {
+			resp.Next = &dto.ServiceLogCursor{File: i, Before: resp.Lines[len(resp.Lines)-1].Offset}
+			break
+		}
+		if !older {
+			break
+		}
+	}
+	return resp, nil
+}
+
+func readJournal(ctx context.Context, source *devops.LogSource, req dto.ServiceLogRequest, filter logfile.Filter) (*dto.ServiceLogResponse, error) {
+	resp := &dto.ServiceLogResponse{Lines: []dto.ServiceLogLine{}}
+	skipped := 0
+	last := ""
+	err := journal(source).Scan(ctx, req.Cursor, filter, func(line logfile.Line) bool {
+		if skipped < req.Offset {
+			skipped++
+			return true
+		}
+		resp.Lines = append(resp.Lines, dto.ServiceLogLine{Time: line.Time, Text: line.Text})
+		last = line.Cursor
+		return len(resp.Lines) < req.Lines
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Lines) == req.Lines {
+		resp.Next = &dto.ServiceLogCursor{Cursor: last}
+	}
+	return resp, nil
+}
+
+// FollowServiceLog streams a runtime log of a service, starting lines back
+// from its end, until ctx is done.
+func (s *DevOpsService) FollowServiceLog(ctx context.Context, configID uint64, req dto.ServiceLogStreamRequest) (<-chan logfile.Event, error) {
+	source, err := s.logSource(ctx, configID, req.Source)
+	if err != nil {
+		return nil, err
+	}
+	if source.Type == "journald" {
+		return journal(source).Follow(ctx, req.Lines)
+	}
+
+	files, err := sourceFiles(source)
+	if err != nil {
+		return nil, err
+	}
+	if source.Type == "file" {
+		files = files[:1] // rotated files don't grow
+	} else {
+		files = slices.DeleteFunc(files, func(path string) bool { return strings.HasSuffix(path, ".gz") })
+	}
+
+	events, err := logfile.FollowAll(ctx, files, req.Lines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %v", err)
+	}
+	return events, nil
+}
+
+func (s *DevOpsService) logSource(ctx context.Context, configID uint64, name string) (*devops.LogSource, error) {
+	repoConfig := s.repo.GetConfig(ctx, configID)
+	if repoConfig == nil {
+		return nil, fmt.Errorf("config not found")
+	}
+	if name == "" {
+		name = defaultLogSource
+	}
+
+	sources := logSources(repoConfig)
+	for i := range sources {
+		if sources[i].Name == name {
+			return &sources[i], nil
+		}
+	}
+	if name == defaultLogSource {
+		return nil, fmt.Errorf("log path not configured")
+	}
+	return nil, fmt.Errorf("log source %q not found", name)
+}
+
+// logSources returns the configured sources, with LogPath as "default".
+func logSources(repoConfig *devops.RepoConfig) []devops.LogSource {
+	sources := slices.Clone(repoConfig.LogSources)
+	hasDefault := slices.ContainsFunc(sources, func(source devops.LogSource) bool { return source.Name == defaultLogSource })
+	if repoConfig.LogPath != "" && !hasDefault {
+		sources = append([]devops.LogSource{{Name: defaultLogSource, Type: "file", Path: repoConfig.LogPath}}, sources...)
+	}
+	if sources == nil {
+		sources = []devops.LogSource{}
+	}
+	return sources
+}
+
+// sourceFiles returns the files of a file or glob source, newest first.
+func sourceFiles(source *devops.LogSource) ([]string, error) {
+	if source.Type == "file" {
+		return logfile.Siblings(source.Path), nil
+	}
+
+	files, err := filepath.Glob(source.Path)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no log files match %s", source.Path)
+	}
+	modTimes := make(map[string]int64, len(files))
+	for _, path := range files {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime().UnixNano()
+		}
+	}
+	slices.SortStableFunc(files, func(a, b string) int {
+		return cmp.Compare(modTimes[b], modTimes[a])
+	})
+	return files, nil
+}
+
+func journal(source *devops.LogSource) *logfile.Journal {
+	return &logfile.Journal{Bin: config.AppConfig.Logs.Journalctl, Unit: source.Unit}
+}
+
+// validateLogSources checks the log sources of a service configuration.
+func validateLogSources(sources []devops.LogSource) error {
+	names := make(map[string]bool)
+	for _, source := range sources {
+		if source.Name == "" {
+			return fmt.Errorf("log source name is required")
+		}
+		if names[source.Name] {
+			return fmt.Errorf("duplicate log source %q", source.Name)
+		}
+		names[source.Name] = true
+
+		switch source.Type {
+		case "file":
+			if source.Path == "" {
+				return fmt.Errorf("log source %q: path is required", source.Name)
+			}
+		case "glob":
+			if _, err := filepath.Match(source.Path, ""); source.Path == "" || err != nil {
+				return fmt.Errorf("log source %q: invalid glob pattern %q", source.Name, source.Path)
+			}
+		case "journald":
+			if source.Unit == "" {
+				return fmt.Errorf("log source %q: unit is required", source.Name)
+			}
+		default:
+			return fmt.Errorf("log source %q: unknown type %q", source.Name, source.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/infrastructure/config"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubJournalctl stands in for journalctl. The unit app.service has the
+// entries c1 to c5, one second apart; c3 is sent as a byte array, like
+// journald sends messages that aren't UTF-8. Any other unit fails. Following
+// prints the last --lines entries and then waits, unless the unit is
+// crash.service, for which it exits with an error.
+const stubJournalctl = `#!/bin/sh
+echo "$@" >> "$0.args"
+cursor= lines=10 follow= unit=
+for a in "$@"; do
+	case $a in
+	--cursor=*) cursor=${a#--cursor=} ;;
+	--lines=*) lines=${a#--lines=} ;;
+	--follow) follow=1 ;;
+	--unit=*) unit=${a#--unit=} ;;
+	esac
+done
+entry() {
+	if [ "$1" = 3 ]; then msg='[108,105,110,101,32,51]'; else msg="\"line $1\""; fi
+	printf '{"__CURSOR":"c%d","__REALTIME_TIMESTAMP":"%d000000","MESSAGE":%s}\n' "$1" $((1700000000 + $1)) "$msg"
+}
+if [ -n "$follow" ]; then
+	for i in 1 2 3 4 5; do
+		[ "$i" -gt $((5 - lines)) ] && entry $i
+	done
+	if [ "$unit" = crash.service ]; then
+		echo "Journal file corrupted" >&2
+		exit 1
+	fi
+	exec sleep 30
+fi
+if [ "$unit" != app.service ]; then
+	echo "No journal files were found." >&2
+	exit 1
+fi
+started=
+[ -z "$cursor" ] && started=1
+for i in 5 4 3 2 1; do
+	[ "c$i" = "$cursor" ] && started=1
+	[ -n "$started" ] && entry $i
+done
+exit 0
+`
+
+// newJournalService returns a service with the stub journalctl and a
+// configuration with the journald sources app and crash.
+func newJournalService(t *testing.T) (*DevOpsService, uint64, string) {
+	t.Helper()
+	s := newTestService(t)
+	bin := filepath.Join(t.TempDir(), "journalctl")
+	if err := os.WriteFile(bin, []byte(stubJournalctl), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	config.AppConfig.Logs.Journalctl = bin
+
+	repoConfig := &devops.RepoConfig{Name: "app", LogSources: []devops.LogSource{
+		{Name: "app", Type: "journald", Unit: "app.service"},
+		{Name: "missing", Type: "journald", Unit: "missing.service"},
+		{Name: "crash", Type: "journald", Unit: "crash.service"},
+	}}
+	if err := s.repo.SaveConfig(context.Background(), repoConfig); err != nil {
+		t.Fatal(err)
+	}
+	return s, repoConfig.ID, bin + ".args"
+}
+
+func TestJournalScan(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       dto.ServiceLogRequest
+		wantLines []string // oldest first
+		wantNext  string
+		wantArgs  []string
+		wantError string
+	}{
+		{
+			name:      "newest page",
+			req:       dto.ServiceLogRequest{Source: "app", Lines: 2},
+			wantLines: []string{"line 4", "line 5"},
+			wantNext:  "c4",
+			wantArgs:  []string{"--unit=app.service", "--output=json", "--reverse"},
+		},
+		{
+			name:      "after cursor",
+			req:       dto.ServiceLogRequest{Source: "app", Lines: 2, Cursor: "c4"},
+			wantLines: []string{"line 2", "line 3"},
+			wantNext:  "c2",
+			wantArgs:  []string{"--cursor=c4"},
+		},
+		{
+			name:      "last page",
+			req:       dto.ServiceLogRequest{Source: "app", Lines: 5, Cursor: "c2"},
+			wantLines: []string{"line 1"},
+		},
+		{
+			name:      "offset",
+			req:       dto.ServiceLogRequest{Source: "app", Lines: 2, Offset: 1},
+			wantLines: []string{"line 3", "line 4"},
+			wantNext:  "c3",
+		},
+		{
+			name:      "grep",
+			req:       dto.ServiceLogRequest{Source: "app", Lines: 10, Grep: "line [135]"},
+			wantLines: []string{"line 1", "line 3", "line 5"},
+		},
+		{
+			name:     "since",
+			req:      dto.ServiceLogRequest{Source: "app", Lines: 10, Since: "2023-11-14"},
+			wantArgs: []string{"--since=2023-11-14 00:00:00"},
+			// The stub ignores --since, so every entry is returned.
+			wantLines: []string{"line 1", "line 2", "line 3", "line 4", "line 5"},
+		},
+		{
+			name:      "journalctl fails",
+			req:       dto.ServiceLogRequest{Source: "missing", Lines: 10},
+			wantError: "No journal files were found.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, id, argsFile := newJournalService(t)
+
+			resp, err := s.GetServiceLog(context.Background(), id, tt.req)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("GetServiceLog() error = %v, want %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var lines []string
+			for _, line := range resp.Lines {
+				lines = append(lines, line.Text)
+				if line.Time == nil {
+					t.Errorf("line %q has no time", line.Text)
+				}
+			}
+			if !slices.Equal(lines, tt.wantLines) {
+				t.Errorf("lines = %q, want %q", lines, tt.wantLines)
+			}
+			next := ""
+			if resp.Next != nil {
+				next = resp.Next.Cursor
+			}
+			if next != tt.wantNext {
+				t.Errorf("next cursor = %q, want %q", next, tt.wantNext)
+			}
+
+			args, _ := os.ReadFile(argsFile)
+			for _, want := range tt.wantArgs {
+				if !strings.Contains(string(args), want) {
+					t.Errorf("journalctl args %q lack %q", args, want)
+				}
+			}
+		})
+	}
+}
+
+func TestJournalFollow(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		lines     int
+		wantLines []string
+		wantError string // of the last event
+	}{
+		{name: "tail", source: "app", lines: 2, wantLines: []string{"line 4", "line 5"}},
+		{name: "no backlog", source: "app", lines: 0},
+		{name: "journalctl exits", source: "crash", lines: 1, wantLines: []string{"line 5"}, wantError: "Journal file corrupted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, id, argsFile := newJournalService(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			events, err := s.FollowServiceLog(ctx, id, dto.ServiceLogStreamRequest{Source: tt.source, Lines: tt.lines})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var lines []string
+			timeout := time.After(5 * time.Second)
+			for len(lines) < len(tt.wantLines) {
+				select {
+				case event := <-events:
+					if event.Type != "line" {
+						t.Fatalf("got %+v before the lines", event)
+					}
+					lines = append(lines, event.Line)
+				case <-timeout:
+					t.Fatalf("got lines %q, want %q", lines, tt.wantLines)
+				}
+			}
+			if !slices.Equal(lines, tt.wantLines) {
+				t.Errorf("lines = %q, want %q", lines, tt.wantLines)
+			}
+
+			if tt.wantError != "" {
+				select {
+				case event := <-events:
+					if event.Type != "error" || !strings.Contains(event.Line, tt.wantError) {
+						t.Errorf("got %+v, want an error with %q", event, tt.wantError)
+					}
+				case <-timeout:
+					t.Fatal("no error event")
+				}
+			}
+
+			for args := ""; !strings.Contains(args, "--follow"); {
+				select {
+				case <-timeout:
+					t.Fatalf("journalctl args %q lack --follow", args)
+				case <-time.After(10 * time.Millisecond):
+					data, _ := os.ReadFile(argsFile)
+					args = string(data)
+				}
+			}
+
+			// Canceling stops journalctl and closes the stream.
+			cancel()
+			for {
+				select {
+				case _, ok := <-events:
+					if !ok {
+						return
+					}
+				case <-timeout:
+					t.Fatal("stream not closed after cancel")
+				}
+			}
+		})
+	}
+}
+
+func TestValidateLogSources(t *testing.T) {
+	tests := []struct {
+		name      string
+		sources   []devops.LogSource
+		wantError string
+	}{
+		{name: "none"},
+		{name: "valid", sources: []devops.LogSource{
+			{Name: "app", Type: "file", Path: "/var/log/app/app.log"},
+			{Name: "workers", Type: "glob", Path: "/var/log/app/worker-*.log"},
+			{Name: "unit", Type: "journald", Unit: "app.service"},
+		}},
+		{name: "no name", sources: []devops.LogSource{{Type: "file", Path: "/a.log"}}, wantError: "name is required"},
+		{name: "duplicate", sources: []devops.LogSource{
+			{Name: "app", Type: "file", Path: "/a.log"},
+			{Name: "app", Type: "file", Path: "/b.log"},
+		}, wantError: `duplicate log source "app"`},
+		{name: "file without path", sources: []devops.LogSource{{Name: "app", Type: "file"}}, wantError: "path is required"},
+		{name: "empty glob", sources: []devops.LogSource{{Name: "app", Type: "glob"}}, wantError: "invalid glob pattern"},
+		{name: "bad glob", sources: []devops.LogSource{{Name: "app", Type: "glob", Path: "/var/log/[app.log"}}, wantError: "invalid glob pattern"},
+		{name: "journald without unit", sources: []devops.LogSource{{Name: "app", Type: "journald"}}, wantError: "unit is required"},
+		{name: "unknown type", sources: []devops.LogSource{{Name: "app", Type: "syslog"}}, wantError: `unknown type "syslog"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateLogSources(tt.sources)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("validateLogSources() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("validateLogSources() = %v, want %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestSourceFilesOrder(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	// Written in name order, modified in another one.
+	modified := map[string]time.Duration{
+		"a.log":    -time.Hour,
+		"b.log":    0,
+		"c.log":    -2 * time.Hour,
+		"c.log.gz": -3 * time.Hour,
+		"skip.txt": time.Minute,
+	}
+	for name, age := range modified {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(age), now.Add(age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := sourceFiles(&devops.LogSource{Type: "glob", Path: filepath.Join(dir, "*.log*")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, path := range files {
+		names = append(names, filepath.Base(path))
+	}
+	if want := []string{"b.log", "a.log", "c.log", "c.log.gz"}; !slices.Equal(names, want) {
+		t.Errorf("files = %q, want newest first %q", names, want)
+	}
+
+	if _, err := sourceFiles(&devops.LogSource{Type: "glob", Path: filepath.Join(dir, "*.json")}); err == nil {
+		t.Error("glob without matches succeeded")
+	}
+}
@@ -0,0 +1,203 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/domain/repository"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+const defaultStatsRange = 30 * 24 * time.Hour
+
+// StatsService derives deployment statistics (DORA metrics, success rate and
+// durations) from the pipeline history.
+type StatsService struct {
+	repo repository.DevOpsRepository
+}
+
+func NewStatsService(repo repository.DevOpsRepository) *StatsService {
+	return &StatsService{repo: repo}
+}
+
+func (s *StatsService) GetStats(ctx context.Context, req dto.StatsRequest) (*dto.StatsResponse, error) {
+	from, err := parseDateParam(req.From, false)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseDateParam(req.To, true)
+	if err != nil {
+		return nil, err
+	}
+	if to == nil {
+		now := time.Now()
+		to = &now
+	}
+	if from == nil {
+		start := to.Add(-defaultStatsRange)
+		from = &start
+	}
+	if !to.After(*from) {
+		return nil, fmt.Errorf("to must be after from")
+	}
+
+	records, _, err := s.repo.ListPipelineRecordsPage(ctx, repository.PipelineFilter{
+		ConfigID: req.ServiceID,
+		Statuses: []string{"success", "failed", "unhealthy"},
+		From:     from,
+		To:       to,
+		SortBy:   "id",
+		Limit:    -1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.StatsResponse{
+		From:     *from,
+		To:       *to,
+		Bucket:   req.Bucket,
+		Global:   computeStats(records, to.Sub(*from)),
+		Buckets:  bucketStats(records, req.Bucket),
+		Services: []dto.ServiceStats{},
+	}
+
+	byService := make(map[uint64][]devops.PipelineRecord)
+	var order []uint64
+	for _, r := range records {
+		if _, ok := byService[r.ConfigID]; !ok {
+			order = append(order, r.ConfigID)
+		}
+		byService[r.ConfigID] = append(byService[r.ConfigID], r)
+	}
+	for _, id := range order {
+		group := byService[id]
+		resp.Services = append(resp.Services, dto.ServiceStats{
+			ConfigID:        id,
+			Name:            group[len(group)-1].RepoName,
+			DeploymentStats: computeStats(group, to.Sub(*from)),
+			Buckets:         bucketStats(group, req.Bucket),
+		})
+	}
+
+	return resp, nil
+}
+
+// computeStats aggregates finished pipelines, oldest first, over a period.
+func computeStats(records []devops.PipelineRecord, period time.Duration) dto.DeploymentStats {
+	var stats dto.DeploymentStats
+	var durations, leadTimes []float64
+	var recoveryTotal float64
+
+	// MTTR is measured per service: from the first failure of a streak to
+	// the next successful deploy of the same service.
+	failingSince := make(map[uint64]time.Time)
+
+	for _, r := range records {
+		stats.Total++
+		durations = append(durations, float64(r.Duration))
+
+		finished := r.CreatedAt
+		if r.FinishedAt != nil {
+			finished = *r.FinishedAt
+		}
+
+		if r.Status == "success" {
+			stats.Succeeded++
+			leadTimes = append(leadTimes, finished.Sub(r.CreatedAt).Seconds())
+			if since, ok := failingSince[r.ConfigID]; ok {
+				recoveryTotal += finished.Sub(since).Seconds()
+				stats.Recoveries++
+				delete(failingSince, r.ConfigID)
+			}
+			continue
+		}
+
+		stats.Failed++
+		if _, ok := failingSince[r.ConfigID]; !ok {
+			failingSince[r.ConfigID] = finished
+		}
+	}
+
+	if stats.Total > 0 {
+		stats.SuccessRate = ratio(stats.Succeeded, stats.Total)
+		stats.ChangeFailureRate = ratio(stats.Failed, stats.Total)
+	}
+	if days := period.Hours() / 24; days > 0 {
+		stats.DeploymentFrequency = round2(float64(stats.Succeeded) / days)
+	}
+	if len(leadTimes) > 0 {
+		var sum float64
+		for _, t := range leadTimes {
+			sum += t
+		}
+		stats.LeadTimeAvg = round2(sum / float64(len(leadTimes)))
+		stats.LeadTimeP50 = percentile(leadTimes, 50)
+	}
+	if stats.Recoveries > 0 {
+		stats.MTTR = round2(recoveryTotal / float64(stats.Recoveries))
+	}
+	stats.DurationP50 = percentile(durations, 50)
+	stats.DurationP95 = percentile(durations, 95)
+
+	return stats
+}
+
+// bucketStats groups records per day or ISO week (starting Monday) in server
+// local time, by creation time.
+func bucketStats(records []devops.PipelineRecord, bucket string) []dto.StatsBucket {
+	period := 24 * time.Hour
+	if bucket == "week" {
+		period = 7 * 24 * time.Hour
+	}
+
+	groups := make(map[time.Time][]devops.PipelineRecord)
+	for _, r := range records {
+		start := bucketStart(r.CreatedAt, bucket)
+		groups[start] = append(groups[start], r)
+	}
+
+	buckets := make([]dto.StatsBucket, 0, len(groups))
+	for start, group := range groups {
+		buckets = append(buckets, dto.StatsBucket{
+			Start:           start,
+			DeploymentStats: computeStats(group, period),
+		})
+	}
+	slices.SortFunc(buckets, func(a, b dto.StatsBucket) int {
+		return a.Start.Compare(b.Start)
+	})
+	return buckets
+}
+
+func bucketStart(t time.Time, bucket string) time.Time {
+	t = t.Local()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	if bucket == "week" {
+		offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+		day = day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+// percentile uses the nearest-rank method.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+func ratio(n, total int) float64 {
+	return round2(float64(n) / float64(total))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
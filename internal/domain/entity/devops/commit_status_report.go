@@ -0,0 +1,22 @@
+package devops
+
+import "time"
+
+// CommitStatusReport is one attempt to post a pipeline result as a commit
+// status to the Git forge.
+type CommitStatusReport struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	PipelineID uint64    `gorm:"index" json:"pipeline_id"`
+	ConfigID   uint64    `json:"config_id"`
+	Forge      string    `gorm:"size:20" json:"forge"`
+	CommitSHA  string    `gorm:"size:40" json:"commit_sha"`
+	State      string    `gorm:"size:20" json:"state"` // pending, success, failure, error
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"` // HTTP status of the forge response, 0 if none
+	Error      string    `gorm:"type:text" json:"error"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (CommitStatusReport) TableName() string {
+	return "devops_commit_status_reports"
+}
@@ -0,0 +1,27 @@
+package devops
+
+import "time"
+
+// DeploySchedule deploys a service on a cron schedule or once at RunAt.
+type DeploySchedule struct {
+	ID             uint64            `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConfigID       uint64            `gorm:"index" json:"config_id"`
+	Name           string            `gorm:"size:100" json:"name"`
+	Cron           string            `gorm:"size:100" json:"cron"`    // recurring schedule, e.g. "0 2 * * *"
+	RunAt          *time.Time        `json:"run_at"`                  // one-shot schedule
+	Timezone       string            `gorm:"size:50" json:"timezone"` // IANA name, defaults to server local time
+	Ref            string            `gorm:"size:100" json:"ref"`
+	Env            map[string]string `gorm:"type:text;serializer:json" json:"env"`
+	Paused         bool              `json:"paused"`
+	NextRunAt      *time.Time        `gorm:"index" json:"next_run_at"` // nil once a one-shot schedule has run
+	LastRunAt      *time.Time        `json:"last_run_at"`
+	LastPipelineID uint64            `json:"last_pipeline_id"`
+	LastError      string            `gorm:"type:text" json:"last_error"`
+	CreatedBy      string            `gorm:"size:100" json:"created_by"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+func (DeploySchedule) TableName() string {
+	return "devops_deploy_schedules"
+}
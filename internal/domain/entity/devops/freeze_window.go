@@ -0,0 +1,24 @@
+package devops
+
+import "time"
+
+// FreezeWindow blocks deploys either on a recurring schedule (Cron + Duration)
+// or during an absolute range (StartAt to EndAt).
+type FreezeWindow struct {
+	ID        uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string     `gorm:"size:100;not null" json:"name"`
+	ConfigID  uint64     `gorm:"index" json:"config_id"`                 // 0 applies to every service
+	Cron      string     `gorm:"size:100" json:"cron"`                   // start of each recurrence, e.g. "0 18 * * 5"
+	Duration  int        `json:"duration"`                               // minutes, used with Cron
+	StartAt   *time.Time `json:"start_at"`                               // absolute range
+	EndAt     *time.Time `json:"end_at"`                                 // absolute range
+	Timezone  string     `gorm:"size:50" json:"timezone"`                // IANA name, defaults to server local time
+	Action    string     `gorm:"size:20;default:'reject'" json:"action"` // reject, queue
+	Enabled   bool       `json:"enabled"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (FreezeWindow) TableName() string {
+	return "devops_freeze_windows"
+}
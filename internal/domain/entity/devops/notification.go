@@ -0,0 +1,36 @@
+package devops
+
+import "time"
+
+// NotificationChannel is a destination for pipeline notifications.
+type NotificationChannel struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name       string    `gorm:"size:100;not null" json:"name"`
+	Type       string    `gorm:"size:20" json:"type"`                         // webhook, slack, dingtalk, feishu, email
+	URL        string    `gorm:"size:500" json:"url"`                         // unused by email
+	Secret     string    `gorm:"size:255" json:"-"`                           // HMAC key for webhook, signing secret for dingtalk/feishu
+	Recipients []string  `gorm:"type:text;serializer:json" json:"recipients"` // email only
+	Template   string    `gorm:"type:text" json:"template"`                   // text/template for the message body, see NotificationTemplateData
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (NotificationChannel) TableName() string {
+	return "devops_notification_channels"
+}
+
+// NotificationRule routes status changes of a service to a channel.
+type NotificationRule struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	ChannelID uint64    `gorm:"index" json:"channel_id"`
+	ConfigID  uint64    `gorm:"index" json:"config_id"`                    // 0 applies to every service
+	Statuses  []string  `gorm:"type:text;serializer:json" json:"statuses"` // empty matches every final status
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (NotificationRule) TableName() string {
+	return "devops_notification_rules"
+}
@@ -0,0 +1,20 @@
+package devops
+
+import "time"
+
+// PipelineArtifact is a file produced by a pipeline and kept on disk.
+type PipelineArtifact struct {
+	ID         uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	PipelineID uint64     `gorm:"index" json:"pipeline_id"`
+	ConfigID   uint64     `gorm:"index" json:"config_id"`
+	Name       string     `gorm:"size:255" json:"name"` // relative path, e.g. bin/flowgo-server
+	Path       string     `gorm:"size:255" json:"-"`    // relative to the artifact store directory
+	Size       int64      `json:"size"`
+	SHA256     string     `gorm:"size:64" json:"sha256"`
+	ExpiresAt  *time.Time `gorm:"index" json:"expires_at"` // nil keeps the artifact forever
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (PipelineArtifact) TableName() string {
+	return "devops_pipeline_artifacts"
+}
@@ -0,0 +1,18 @@
+package devops
+
+import "time"
+
+// PipelineAttempt is one run of the deploy script within a pipeline.
+type PipelineAttempt struct {
+	ID         uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	PipelineID uint64     `gorm:"index" json:"pipeline_id"`
+	Attempt    int        `json:"attempt"`
+	ExitCode   int        `json:"exit_code"` // -1 when the script could not be started
+	Log        string     `gorm:"type:text" json:"log"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+func (PipelineAttempt) TableName() string {
+	return "devops_pipeline_attempts"
+}
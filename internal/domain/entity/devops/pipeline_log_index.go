@@ -0,0 +1,15 @@
+package devops
+
+import "time"
+
+// PipelineLogIndex records that the log of a pipeline is in the full-text
+// search index.
+type PipelineLogIndex struct {
+	PipelineID uint64    `gorm:"primaryKey;autoIncrement:false" json:"pipeline_id"`
+	Lines      int       `json:"lines"` // indexed, non-empty lines
+	IndexedAt  time.Time `json:"indexed_at"`
+}
+
+func (PipelineLogIndex) TableName() string {
+	return "devops_pipeline_log_index"
+}
@@ -3,21 +3,69 @@ package devops
 import "time"
 
 type PipelineRecord struct {
-	ID            uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	ConfigID      uint64     `json:"config_id"`
-	RepoName      string     `gorm:"size:100" json:"repo_name"`
-	Status        string     `gorm:"size:20;default:'pending'" json:"status"` // pending, running, success, failed, canceled
-	Ref           string     `gorm:"size:100" json:"ref"`                     // branch or tag
-	CommitSHA     string     `gorm:"size:40" json:"commit_sha"`
-	CommitMsg     string     `gorm:"type:text" json:"commit_msg"`
-	Author        string     `gorm:"size:100" json:"author"`
-	TriggerSource string     `gorm:"size:20;default:'manual'" json:"trigger_source"` // manual, webhook
-	Duration      int64      `json:"duration"`                                       // seconds
-	StartedAt     *time.Time `json:"started_at"`
-	FinishedAt    *time.Time `json:"finished_at"`
-	CreatedAt     time.Time  `json:"created_at"`
+	ID             uint64            `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConfigID       uint64            `json:"config_id"`
+	RepoName       string            `gorm:"size:100" json:"repo_name"`
+	Status         string            `gorm:"size:20;default:'pending'" json:"status"` // queued, awaiting_approval, pending, running, success, failed, unhealthy, canceled, rejected, expired
+	Ref            string            `gorm:"size:100" json:"ref"`                     // branch or tag
+	CommitSHA      string            `gorm:"size:40" json:"commit_sha"`
+	CommitMsg      string            `gorm:"type:text" json:"commit_msg"`
+	Author         string            `gorm:"size:100" json:"author"`
+	TriggeredBy    string            `gorm:"size:100" json:"triggered_by"`
+	TriggerSource  string            `gorm:"size:20;default:'manual'" json:"trigger_source"`   // manual, webhook, ci_cd, rollback, rerun, schedule
+	RollbackOf     uint64            `json:"rollback_of"`                                      // pipeline whose failed health checks triggered this rollback
+	RerunOf        uint64            `json:"rerun_of"`                                         // pipeline this one was cloned from, by a re-run or rollback
+	ScheduleID     uint64            `json:"schedule_id"`                                      // schedule that started this pipeline
+	Args           []string          `gorm:"type:text;serializer:json" json:"args"`            // deploy script arguments
+	Env            map[string]string `gorm:"type:text;serializer:json" json:"env"`             // extra script environment
+	ConfigSnapshot *RepoConfig       `gorm:"type:text;serializer:json" json:"config_snapshot"` // service config at trigger time
+	Approval       PipelineApproval  `gorm:"embedded;embeddedPrefix:approval_" json:"approval"`
+	FreezeOverride FreezeOverride    `gorm:"embedded;embeddedPrefix:freeze_override_" json:"freeze_override"`
+	Token          string            `gorm:"size:64" json:"-"`                           // authenticates the deploy script against the artifact API
+	ChangelogFrom  string            `gorm:"size:40" json:"changelog_from"`              // SHA of the previous release
+	Changelog      []ChangelogCommit `gorm:"type:text;serializer:json" json:"changelog"` // commits since the previous release, newest first
+	Attempts       int               `json:"attempts"`                                   // deploy script runs, see PipelineAttempt
+	Log            string            `gorm:"type:text" json:"log"`
+	LogArchive     string            `gorm:"size:255" json:"log_archive"` // gzip file in retention.archive_dir the log was moved to
+	LogMarks       []LogMark         `gorm:"type:text;serializer:json" json:"-"`
+	Duration       int64             `json:"duration"` // seconds
+	StartedAt      *time.Time        `json:"started_at"`
+	FinishedAt     *time.Time        `json:"finished_at"`
+	CreatedAt      time.Time         `json:"created_at"`
 }
 
 func (PipelineRecord) TableName() string {
 	return "devops_pipeline_records"
 }
+
+// PipelineApproval records the manual approval gate of a pipeline.
+type PipelineApproval struct {
+	Decision  string     `gorm:"size:20" json:"decision"` // approved, rejected, expired
+	DecidedBy string     `gorm:"size:100" json:"decided_by"`
+	Comment   string     `gorm:"type:text" json:"comment"`
+	DecidedAt *time.Time `json:"decided_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ChangelogCommit is a commit deployed by a pipeline.
+type ChangelogCommit struct {
+	SHA     string    `json:"sha"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// LogMark gives the time the lines of a pipeline log from Line (1-based) up
+// to the next mark were written at, in Unix seconds.
+type LogMark struct {
+	Line int   `json:"l"`
+	Time int64 `json:"t"`
+}
+
+// FreezeOverride records an admin deploying through an active freeze window.
+type FreezeOverride struct {
+	By       string `gorm:"size:100" json:"by"`
+	Reason   string `gorm:"type:text" json:"reason"`
+	WindowID uint64 `json:"window_id"`
+}
@@ -0,0 +1,22 @@
+package devops
+
+import "time"
+
+// Release is a version of a service deployed by a successful pipeline.
+// At most one release per service is active: the one currently deployed.
+type Release struct {
+	ID          uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConfigID    uint64     `gorm:"index" json:"config_id"`
+	ServiceName string     `gorm:"size:100" json:"service_name"`
+	Version     string     `gorm:"size:100" json:"version"` // tag, branch or script argument
+	CommitSHA   string     `gorm:"size:40" json:"commit_sha"`
+	PipelineID  uint64     `gorm:"index" json:"pipeline_id"`
+	Active      bool       `json:"active"`
+	DeployedAt  time.Time  `json:"deployed_at"`
+	RetiredAt   *time.Time `json:"retired_at"` // when a later release replaced this one
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (Release) TableName() string {
+	return "devops_releases"
+}
@@ -3,15 +3,65 @@ package devops
 import "time"
 
 type RepoConfig struct {
-	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name         string    `gorm:"size:100;not null" json:"name"`
-	RepoURL      string    `gorm:"size:255;not null" json:"repo_url"`
-	DeployScript string    `gorm:"size:255;not null" json:"deploy_script"`
-	LogPath      string    `gorm:"size:255" json:"log_path"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID              uint64            `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name            string            `gorm:"size:100;not null" json:"name"`
+	RepoURL         string            `gorm:"size:255;not null" json:"repo_url"`
+	DeployScript    string            `gorm:"size:255;not null" json:"deploy_script"`
+	LogPath         string            `gorm:"size:255" json:"log_path"`
+	LogSources      []LogSource       `gorm:"type:text;serializer:json" json:"log_sources"`
+	HealthCheck     HealthCheckPolicy `gorm:"embedded;embeddedPrefix:health_" json:"health_check"`
+	Retry           RetryPolicy       `gorm:"embedded;embeddedPrefix:retry_" json:"retry"`
+	RequireApproval bool              `json:"require_approval"` // new pipelines wait in awaiting_approval
+	ApprovalTimeout int               `json:"approval_timeout"` // minutes before an unapproved pipeline expires, defaults to 60
+	Forge           ForgeSettings     `gorm:"embedded;embeddedPrefix:forge_" json:"forge"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
 }
 
 func (RepoConfig) TableName() string {
 	return "devops_repo_configs"
 }
+
+// HealthCheckPolicy describes the post-deploy checks and how long to keep trying them.
+type HealthCheckPolicy struct {
+	Checks   []HealthCheck `gorm:"type:text;serializer:json" json:"checks"`
+	Retries  int           `json:"retries"`  // attempts per check round, 0 means 1
+	Interval int           `json:"interval"` // seconds between attempts
+	Deadline int           `json:"deadline"` // seconds, overall budget for all attempts
+}
+
+// RetryPolicy controls how often a failing deploy script is re-run.
+type RetryPolicy struct {
+	MaxAttempts int   `json:"max_attempts"`                                // total attempts, 0 means 1
+	Backoff     int   `json:"backoff"`                                     // seconds before the first retry, doubled on each further retry
+	ExitCodes   []int `gorm:"type:text;serializer:json" json:"exit_codes"` // retryable exit codes, empty means any failure
+}
+
+// HealthCheck is a single probe run after a successful deploy script.
+type HealthCheck struct {
+	Type         string `json:"type"`                    // http, tcp, command
+	URL          string `json:"url,omitempty"`           // http
+	ExpectStatus int    `json:"expect_status,omitempty"` // http, defaults to 200
+	ExpectBody   string `json:"expect_body,omitempty"`   // http, substring match
+	Address      string `json:"address,omitempty"`       // tcp, host:port
+	Command      string `json:"command,omitempty"`       // command, run via /bin/bash -c
+	Timeout      int    `json:"timeout,omitempty"`       // seconds, defaults to 5
+}
+
+// LogSource is a named runtime log of a service. LogPath is available as the
+// source "default" unless a source takes that name.
+type LogSource struct {
+	Name string `json:"name"`
+	Type string `json:"type"`           // file, glob, journald
+	Path string `json:"path,omitempty"` // file: the log file; glob: a pattern such as /var/log/app/*.log
+	Unit string `json:"unit,omitempty"` // journald: the systemd unit
+}
+
+// ForgeSettings enables reporting pipeline results as commit statuses on the
+// Git forge hosting the repository.
+type ForgeSettings struct {
+	Type    string `gorm:"size:20" json:"type"`     // github, gitea; empty disables reporting
+	APIURL  string `gorm:"size:255" json:"api_url"` // defaults to https://api.github.com for github, e.g. https://gitea.example.com/api/v1
+	Token   string `gorm:"size:255" json:"-"`
+	Context string `gorm:"size:100" json:"context"` // status name on the commit, defaults to "deployment"
+}
@@ -0,0 +1,24 @@
+package devops
+
+import "time"
+
+// RetentionRun reports one run of the retention policy on pipeline records.
+type RetentionRun struct {
+	ID               uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	DryRun           bool      `json:"dry_run"`
+	Archived         int       `json:"archived"`           // pipelines whose log was moved to a gzip archive
+	ArchivedLogBytes int64     `json:"archived_log_bytes"` // log bytes moved out of the database
+	ArchiveBytes     int64     `json:"archive_bytes"`      // size of the archives written, estimated for dry runs
+	Purged           int       `json:"purged"`             // pipelines deleted
+	PurgedBytes      int64     `json:"purged_bytes"`       // log bytes and archives deleted with them
+	ReclaimedBytes   int64     `json:"reclaimed_bytes"`
+	Vacuumed         bool      `json:"vacuumed"` // the database file was rebuilt, see RetentionConfig.Vacuum
+	Errors           int       `json:"errors"`
+	LastError        string    `gorm:"type:text" json:"last_error"`
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+}
+
+func (RetentionRun) TableName() string {
+	return "devops_retention_runs"
+}
@@ -3,8 +3,82 @@ package repository
 import (
 	"OpsGo/internal/domain/entity/devops"
 	"context"
+	"time"
 )
 
+// PipelineFilter narrows ListPipelineRecordsPage; zero values match everything.
+type PipelineFilter struct {
+	ConfigID      uint64
+	Statuses      []string
+	TriggerSource string
+	Ref           string
+	Author        string
+	From          *time.Time
+	To            *time.Time
+	SortBy        string // column name, validated by the caller
+	SortDesc      bool
+	Offset        int
+	Limit         int
+}
+
+// LogSearchFilter narrows SearchPipelineLogs. Query is an FTS5 match
+// expression; the other zero values match everything.
+type LogSearchFilter struct {
+	Query    string
+	ConfigID uint64
+	From     *time.Time
+	To       *time.Time
+	SortDesc bool
+	Offset   int
+	Limit    int
+}
+
+// LogSearchMatch is a pipeline whose log matches a search.
+type LogSearchMatch struct {
+	PipelineID uint64
+	ConfigID   uint64
+	RepoName   string
+	Status     string
+	Ref        string
+	CommitSHA  string
+	CreatedAt  time.Time
+	Matches    int // matching lines
+}
+
+// Snippets of LogSearchHit enclose the matched terms in these markers.
+const (
+	MatchStart = "\x01"
+	MatchEnd   = "\x02"
+)
+
+// LogSearchHit is a matching line of a pipeline log.
+type LogSearchHit struct {
+	Line    int // 1-based
+	Snippet string
+}
+
+// RetentionFilter selects the finished pipelines of ListRetentionCandidates:
+// the ones that aren't among the KeepLast most recent of their service.
+type RetentionFilter struct {
+	KeepLast      int
+	Statuses      []string
+	CreatedBefore *time.Time
+	InlineLog     bool // only pipelines with a log in the database
+	KeepActive    bool // skip the pipelines of active releases
+	AfterID       uint64
+	Limit         int
+}
+
+// RetentionCandidate is a pipeline the retention policy may act on.
+type RetentionCandidate struct {
+	ID         uint64
+	ConfigID   uint64
+	LogArchive string
+	LogSize    int64 // bytes of the logs of the pipeline and its attempts in the database
+	OwnLogSize int64 // bytes of LogSize in the log of the pipeline itself
+	CreatedAt  time.Time
+}
+
 type DevOpsRepository interface {
 	SaveConfig(ctx context.Context, config *devops.RepoConfig) error
 	GetConfig(ctx context.Context, id uint64) *devops.RepoConfig
@@ -16,4 +90,76 @@ type DevOpsRepository interface {
 	UpdatePipelineRecord(ctx context.Context, record *devops.PipelineRecord) error
 	GetPipelineRecord(ctx context.Context, id uint64) *devops.PipelineRecord
 	ListPipelineRecords(ctx context.Context, limit int) ([]devops.PipelineRecord, error)
+	ListPipelineRecordsPage(ctx context.Context, filter PipelineFilter) ([]devops.PipelineRecord, int64, error)
+	ListPipelineRecordsByStatus(ctx context.Context, status string) ([]devops.PipelineRecord, error)
+	GetLastSuccessfulPipeline(ctx context.Context, configID uint64, beforeID uint64) *devops.PipelineRecord
+	// ClaimPipelineStatus moves a pipeline from status from to status to and
+	// reports whether it was still in from, so that of several instances
+	// acting on a pipeline only one does.
+	ClaimPipelineStatus(ctx context.Context, id uint64, from, to string) (bool, error)
+
+	SaveFreezeWindow(ctx context.Context, window *devops.FreezeWindow) error
+	ListFreezeWindows(ctx context.Context) ([]devops.FreezeWindow, error)
+	ListActiveFreezeWindows(ctx context.Context, configID uint64) ([]devops.FreezeWindow, error)
+	DeleteFreezeWindow(ctx context.Context, id uint64) error
+
+	SaveSchedule(ctx context.Context, schedule *devops.DeploySchedule) error
+	GetSchedule(ctx context.Context, id uint64) *devops.DeploySchedule
+	ListSchedules(ctx context.Context) ([]devops.DeploySchedule, error)
+	ListDueSchedules(ctx context.Context, now time.Time) ([]devops.DeploySchedule, error)
+	// ClaimScheduleRun moves the next run of a schedule from due to next and
+	// reports whether no other instance claimed the due run first.
+	ClaimScheduleRun(ctx context.Context, id uint64, due time.Time, next *time.Time) (bool, error)
+	// SaveScheduleRun stores the outcome of the last run of a schedule.
+	SaveScheduleRun(ctx context.Context, schedule *devops.DeploySchedule) error
+	DeleteSchedule(ctx context.Context, id uint64) error
+
+	SaveArtifact(ctx context.Context, artifact *devops.PipelineArtifact) error
+	GetArtifact(ctx context.Context, id uint64) *devops.PipelineArtifact
+	GetArtifactByName(ctx context.Context, pipelineID uint64, name string) *devops.PipelineArtifact
+	ListArtifacts(ctx context.Context, pipelineID uint64) ([]devops.PipelineArtifact, error)
+	ListExpiredArtifacts(ctx context.Context, now time.Time) ([]devops.PipelineArtifact, error)
+	DeleteArtifact(ctx context.Context, id uint64) error
+
+	// ActivateRelease stores a new release and retires the active one of the service.
+	ActivateRelease(ctx context.Context, release *devops.Release) error
+	GetActiveRelease(ctx context.Context, configID uint64) *devops.Release
+	ListReleases(ctx context.Context, configID uint64) ([]devops.Release, error)
+
+	SaveNotificationChannel(ctx context.Context, channel *devops.NotificationChannel) error
+	GetNotificationChannel(ctx context.Context, id uint64) *devops.NotificationChannel
+	ListNotificationChannels(ctx context.Context) ([]devops.NotificationChannel, error)
+	DeleteNotificationChannel(ctx context.Context, id uint64) error
+	SaveNotificationRule(ctx context.Context, rule *devops.NotificationRule) error
+	ListNotificationRules(ctx context.Context) ([]devops.NotificationRule, error)
+	ListEnabledNotificationRules(ctx context.Context, configID uint64) ([]devops.NotificationRule, error)
+	DeleteNotificationRule(ctx context.Context, id uint64) error
+
+	CreateCommitStatusReport(ctx context.Context, report *devops.CommitStatusReport) error
+	ListCommitStatusReports(ctx context.Context, pipelineID uint64) ([]devops.CommitStatusReport, error)
+
+	CreatePipelineAttempt(ctx context.Context, attempt *devops.PipelineAttempt) error
+	ListPipelineAttempts(ctx context.Context, pipelineID uint64) ([]devops.PipelineAttempt, error)
+
+	// IndexPipelineLog replaces the search index entries of a pipeline log.
+	// It returns the number of non-empty lines left out past the index limit.
+	IndexPipelineLog(ctx context.Context, pipelineID uint64, log string) (int, error)
+	// ListUnindexedPipelineIDs returns pipelines in one of statuses with a log
+	// that is not in the search index, oldest first.
+	ListUnindexedPipelineIDs(ctx context.Context, statuses []string, limit int) ([]uint64, error)
+	SearchPipelineLogs(ctx context.Context, filter LogSearchFilter) ([]LogSearchMatch, int64, error)
+	ListLogSearchHits(ctx context.Context, query string, pipelineID uint64, limit int) ([]LogSearchHit, error)
+
+	// ListRetentionCandidates returns matching pipelines by ascending ID.
+	ListRetentionCandidates(ctx context.Context, filter RetentionFilter) ([]RetentionCandidate, error)
+	// ArchivePipelineLog removes the log of a pipeline from the database,
+	// recording the archive it was moved to. Attempt logs are kept.
+	ArchivePipelineLog(ctx context.Context, pipelineID uint64, archive string) error
+	// DeletePipelineRecord deletes a pipeline with its attempts, artifact and
+	// commit status records, retired releases and search index entries.
+	DeletePipelineRecord(ctx context.Context, pipelineID uint64) error
+	// Vacuum rebuilds the database file without the pages freed by deletes.
+	Vacuum(ctx context.Context) error
+	CreateRetentionRun(ctx context.Context, run *devops.RetentionRun) error
+	ListRetentionRuns(ctx context.Context, limit int) ([]devops.RetentionRun, error)
 }
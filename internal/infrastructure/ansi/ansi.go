@@ -0,0 +1,197 @@
+// Package ansi cleans up the terminal output of build tools for display:
+// carriage return overwrites, ANSI escape sequences and runaway lines.
+package ansi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Span is a run of text with the same SGR attributes.
+type Span struct {
+	Text      string `json:"text"`
+	FG        string `json:"fg,omitempty"` // red, bright-red, ..., a 256-color palette index or #rrggbb
+	BG        string `json:"bg,omitempty"`
+	Bold      bool   `json:"bold,omitempty"`
+	Dim       bool   `json:"dim,omitempty"`
+	Italic    bool   `json:"italic,omitempty"`
+	Underline bool   `json:"underline,omitempty"`
+}
+
+var colorNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// Strip removes escape sequences and control characters other than tab and
+// newline.
+func Strip(s string) string {
+	text, _ := parse(s, false)
+	return text
+}
+
+// Parse removes escape sequences like Strip and returns the text as spans
+// with the colors and styles SGR sequences gave them. The spans are nil when
+// the text has no styles.
+func Parse(s string) (string, []Span) {
+	return parse(s, true)
+}
+
+func parse(s string, withSpans bool) (string, []Span) {
+	var text strings.Builder
+	var spans []Span
+	var current Span // attributes in effect, Text unused
+	styled := false  // some text has attributes
+	start := 0       // of the text of the current span
+
+	flush := func() {
+		if text.Len() > start {
+			span := current
+			span.Text = text.String()[start:]
+			spans = append(spans, span)
+			start = text.Len()
+			styled = styled || current != Span{}
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != 0x1b {
+			if c >= 0x20 && c != 0x7f || c == '\t' || c == '\n' {
+				text.WriteByte(c)
+			}
+			continue
+		}
+		end := sequenceEnd(s, i)
+		if end < 0 {
+			break
+		}
+		if s[i+1] == '[' && s[end-1] == 'm' && withSpans {
+			next := applySGR(current, s[i+2:end-1])
+			if next != current {
+				flush()
+				current = next
+			}
+		}
+		i = end - 1
+	}
+
+	if !withSpans {
+		return text.String(), nil
+	}
+	flush()
+	if !styled {
+		return text.String(), nil
+	}
+	return text.String(), spans
+}
+
+// sequenceEnd returns the end of the escape sequence starting at s[i], or -1
+// if s ends before it does.
+func sequenceEnd(s string, i int) int {
+	if i+1 >= len(s) {
+		return -1
+	}
+	switch s[i+1] {
+	case '[': // CSI: parameters, intermediates and a final byte
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j + 1
+			}
+		}
+	case ']', 'P', '_', '^': // OSC and other strings, ended by BEL or ST
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == 0x07 {
+				return j + 1
+			}
+			if s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2
+			}
+		}
+	case '(', ')', '*', '+': // character set designation
+		if i+2 < len(s) {
+			return i + 3
+		}
+	default:
+		return i + 2
+	}
+	return -1
+}
+
+// applySGR returns the attributes after the Select Graphic Rendition
+// parameters params.
+func applySGR(span Span, params string) Span {
+	codes := strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' })
+	if len(codes) == 0 {
+		return Span{}
+	}
+	for k := 0; k < len(codes); k++ {
+		code, err := strconv.Atoi(codes[k])
+		if err != nil {
+			continue
+		}
+		switch {
+		case code == 0:
+			span = Span{}
+		case code == 1:
+			span.Bold = true
+		case code == 2:
+			span.Dim = true
+		case code == 3:
+			span.Italic = true
+		case code == 4:
+			span.Underline = true
+		case code == 22:
+			span.Bold, span.Dim = false, false
+		case code == 23:
+			span.Italic = false
+		case code == 24:
+			span.Underline = false
+		case code >= 30 && code <= 37:
+			span.FG = colorNames[code-30]
+		case code >= 90 && code <= 97:
+			span.FG = "bright-" + colorNames[code-90]
+		case code == 39:
+			span.FG = ""
+		case code >= 40 && code <= 47:
+			span.BG = colorNames[code-40]
+		case code >= 100 && code <= 107:
+			span.BG = "bright-" + colorNames[code-100]
+		case code == 49:
+			span.BG = ""
+		case code == 38 || code == 48:
+			color, n := extendedColor(codes[k+1:])
+			k += n
+			if code == 38 {
+				span.FG = color
+			} else {
+				span.BG = color
+			}
+		}
+	}
+	return span
+}
+
+// extendedColor parses the arguments of SGR 38 and 48, 5;n or 2;r;g;b, and
+// returns the color and the number of codes used.
+func extendedColor(codes []string) (string, int) {
+	if len(codes) == 0 {
+		return "", 0
+	}
+	switch codes[0] {
+	case "5":
+		if len(codes) < 2 {
+			return "", len(codes)
+		}
+		return codes[1], 2
+	case "2":
+		if len(codes) < 4 {
+			return "", len(codes)
+		}
+		var rgb [3]int
+		for i := range rgb {
+			rgb[i], _ = strconv.Atoi(codes[i+1])
+		}
+		return fmt.Sprintf("#%02x%02x%02x", rgb[0]&0xff, rgb[1]&0xff, rgb[2]&0xff), 4
+	default:
+		return "", 1
+	}
+}
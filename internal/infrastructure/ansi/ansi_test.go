@@ -0,0 +1,51 @@
+package ansi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		text  string
+		spans []Span
+	}{
+		{"plain", "hello\n", "hello\n", nil},
+		{"reset", "\x1b[31mred\x1b[0m plain", "red plain", []Span{{Text: "red", FG: "red"}, {Text: " plain"}}},
+		{"empty reset", "\x1b[1;32mok\x1b[m.", "ok.", []Span{{Text: "ok", FG: "green", Bold: true}, {Text: "."}}},
+		{"nested", "\x1b[1mbold \x1b[32mgreen\x1b[22m normal\x1b[39m",
+			"bold green normal", []Span{
+				{Text: "bold ", Bold: true},
+				{Text: "green", FG: "green", Bold: true},
+				{Text: " normal", FG: "green"},
+			}},
+		{"bright and underline", "\x1b[91;4mu\x1b[24;104mb", "ub", []Span{
+			{Text: "u", FG: "bright-red", Underline: true},
+			{Text: "b", FG: "bright-red", BG: "bright-blue"},
+		}},
+		{"extended colors", "\x1b[38;5;208mo\x1b[48;2;255;0;10mx\x1b[49;3md", "oxd", []Span{
+			{Text: "o", FG: "208"},
+			{Text: "x", FG: "208", BG: "#ff000a"},
+			{Text: "d", FG: "208", Italic: true},
+		}},
+		{"truncated extended color", "\x1b[38;5mx", "x", nil},
+		{"style without text", "\x1b[31m\x1b[0mx", "x", nil},
+		{"other sequences", "\x1b]0;title\x07a\x1b[2Kb\x1b(Bc\x1b]8;;http://x\x1b\\d", "abcd", nil},
+		{"control characters", "a\x08b\tc\x07d\x7f", "ab\tcd", nil},
+		{"partial sequence at the end", "ok\x1b[3", "ok", nil},
+		{"lone escape at the end", "ok\x1b", "ok", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, spans := Parse(tt.in)
+			if text != tt.text || !reflect.DeepEqual(spans, tt.spans) {
+				t.Errorf("Parse(%q) = %q, %+v, want %q, %+v", tt.in, text, spans, tt.text, tt.spans)
+			}
+			if stripped := Strip(tt.in); stripped != tt.text {
+				t.Errorf("Strip(%q) = %q, want %q", tt.in, stripped, tt.text)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package ansi
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+const readerBufferSize = 64 << 10
+
+// Reader reads lines of terminal output. A carriage return not followed by a
+// newline starts the line over, as progress bars redraw themselves, so that
+// only the last state of a line is returned; this happens as the line is
+// read, which keeps a progress bar that never ends its line from growing
+// without bound. Lines longer than the limit are cut and marked.
+type Reader struct {
+	r   *bufio.Reader
+	max int
+}
+
+// NewReader returns a Reader cutting lines after max bytes, 0 for no limit.
+func NewReader(r io.Reader, max int) *Reader {
+	return &Reader{r: bufio.NewReaderSize(r, readerBufferSize), max: max}
+}
+
+// ReadLine returns the next line with its newline, which only the last line
+// of the output may lack. Line endings are normalized to "\n". At the end of
+// the output it returns io.EOF, with the last line if it isn't empty.
+func (r *Reader) ReadLine() (string, error) {
+	var line []byte
+	cut := 0 // bytes dropped beyond the limit
+	for {
+		frag, err := r.r.ReadSlice('\n')
+		line = append(line, frag...)
+
+		body := bytes.TrimRight(line, "\r\n")
+		if i := bytes.LastIndexByte(body, '\r'); i >= 0 {
+			line = append(line[:0], line[i+1:]...)
+			cut = 0
+		}
+		if r.max > 0 && len(line) > r.max {
+			// Keep the newline of a line complete in this fragment.
+			end := bytes.HasSuffix(line, []byte("\n"))
+			n := r.max
+			for n > 0 && !utf8.RuneStart(line[n]) {
+				n--
+			}
+			// Nor in the middle of an escape sequence.
+			if i := bytes.LastIndexByte(line[:n], 0x1b); i >= 0 && sequenceEnd(string(line[:n]), i) < 0 {
+				n = i
+			}
+			cut += len(line) - n
+			line = line[:n]
+			if end {
+				line = append(line, '\n')
+				cut--
+			}
+		}
+
+		switch err {
+		case bufio.ErrBufferFull:
+			continue
+		case nil:
+			return finishLine(line, cut), nil
+		default:
+			if len(line) == 0 {
+				return "", err
+			}
+			return finishLine(line, cut), err
+		}
+	}
+}
+
+// finishLine normalizes the line ending and marks a cut line.
+func finishLine(line []byte, cut int) string {
+	newline := bytes.HasSuffix(line, []byte("\n"))
+	line = bytes.TrimRight(line, "\r\n")
+	if cut > 0 {
+		line = fmt.Appendf(line, " [%d bytes cut]", cut)
+	}
+	if newline {
+		line = append(line, '\n')
+	}
+	return string(line)
+}
@@ -0,0 +1,75 @@
+package ansi
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		max   int
+		lines []string
+	}{
+		{"lines", "a\nb\n", 0, []string{"a\n", "b\n"}},
+		{"last line without newline", "a\nb", 0, []string{"a\n", "b"}},
+		{"crlf", "done\r\n", 0, []string{"done\n"}},
+		{"carriage return overwrites", "10%\r50%\r100%\nnext\n", 0, []string{"100%\n", "next\n"}},
+		{"trailing carriage return", "50%\r", 0, []string{"50%"}},
+		{"overwrite past the limit", "xxxxxxxxxx\rok\n", 5, []string{"ok\n"}},
+		{"long line", "abcdefgh\nok\n", 5, []string{"abcde [3 bytes cut]\n", "ok\n"}},
+		{"long last line", "abcdefgh", 5, []string{"abcde [3 bytes cut]"}},
+		{"exact limit", "abcde\n", 5, []string{"abcde\n"}},
+		{"cut before a rune", "aé日x\n", 4, []string{"aé [4 bytes cut]\n"}},
+		{"cut before an escape sequence", "ab\x1b[31mcd\n", 4, []string{"ab [7 bytes cut]\n"}},
+		{"complete escape sequence kept", "\x1b[1mab\x1b[0m\n", 6, []string{"\x1b[1mab [4 bytes cut]\n"}},
+	}
+	readers := map[string]func(io.Reader) io.Reader{
+		"whole":    func(r io.Reader) io.Reader { return r },
+		"one byte": iotest.OneByteReader,
+		"half":     iotest.HalfReader,
+	}
+	for _, tt := range tests {
+		for name, wrap := range readers {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				r := NewReader(wrap(strings.NewReader(tt.in)), tt.max)
+				var lines []string
+				for {
+					line, err := r.ReadLine()
+					if err == io.EOF {
+						if line != "" {
+							lines = append(lines, line)
+						}
+						break
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+					lines = append(lines, line)
+				}
+				if !slices.Equal(lines, tt.lines) {
+					t.Errorf("lines %q, want %q", lines, tt.lines)
+				}
+			})
+		}
+	}
+}
+
+// A progress bar that never ends its line is reduced as it is read.
+func TestReaderLongProgressBar(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 20000; i++ {
+		b.WriteString("\rprogress ")
+		b.WriteString(strings.Repeat("#", i%50))
+	}
+	b.WriteString("\rdone\n")
+
+	line, err := NewReader(strings.NewReader(b.String()), 1000).ReadLine()
+	if err != nil || line != "done\n" {
+		t.Errorf("ReadLine() = %q, %v, want done", line, err)
+	}
+}
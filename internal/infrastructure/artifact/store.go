@@ -0,0 +1,105 @@
+package artifact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Store keeps pipeline artifacts on disk under <dir>/<pipelineID>/<name>.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Object describes a stored artifact file.
+type Object struct {
+	Path   string // relative to the store directory
+	Size   int64
+	SHA256 string
+}
+
+// PipelineDir returns the directory holding the artifacts of a pipeline.
+func (s *Store) PipelineDir(pipelineID uint64) string {
+	return filepath.Join(s.dir, fmt.Sprint(pipelineID))
+}
+
+// Save writes r as artifact name of the pipeline, computing its checksum.
+// Writes beyond maxSize bytes fail; maxSize <= 0 disables the limit.
+func (s *Store) Save(pipelineID uint64, name string, r io.Reader, maxSize int64) (*Object, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	rel := filepath.Join(fmt.Sprint(pipelineID), filepath.FromSlash(name))
+	path := filepath.Join(s.dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if maxSize > 0 {
+		r = io.LimitReader(r, maxSize+1)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && size > maxSize {
+		return nil, fmt.Errorf("artifact exceeds the %d byte limit", maxSize)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+
+	return &Object{
+		Path:   filepath.ToSlash(rel),
+		Size:   size,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// FullPath resolves a stored relative path.
+func (s *Store) FullPath(rel string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(rel))
+}
+
+// Delete removes a stored file and its pipeline directory once empty.
+func (s *Store) Delete(rel string) error {
+	path := s.FullPath(rel)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	// Best effort: fails while other artifacts remain.
+	os.Remove(filepath.Dir(path))
+	return nil
+}
+
+// ValidateName rejects artifact names that would escape the pipeline directory.
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("artifact name is required")
+	}
+	clean := filepath.ToSlash(filepath.Clean(filepath.FromSlash(name)))
+	if filepath.IsAbs(name) || clean != name || clean == "." || strings.HasPrefix(clean, "../") || clean == ".." {
+		return fmt.Errorf("invalid artifact name %q", name)
+	}
+	return nil
+}
@@ -10,10 +10,18 @@ import (
 
 // Config 应用配置
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
-	Redis    RedisConfig    `yaml:"redis"`
-	JWT      JWTConfig      `yaml:"jwt"`
+	Server      ServerConfig      `yaml:"server"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Redis       RedisConfig       `yaml:"redis"`
+	JWT         JWTConfig         `yaml:"jwt"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Artifacts   ArtifactsConfig   `yaml:"artifacts"`
+	Git         GitConfig         `yaml:"git"`
+	Notify      NotifyConfig      `yaml:"notify"`
+	Broadcaster BroadcasterConfig `yaml:"broadcaster"`
+	Logs        LogsConfig        `yaml:"logs"`
+	PipelineLog PipelineLogConfig `yaml:"pipeline_log"`
+	Retention   RetentionConfig   `yaml:"retention"`
 }
 
 // ServerConfig 服务器配置
@@ -22,6 +30,8 @@ type ServerConfig struct {
 	Mode         string `yaml:"mode"`
 	ReadTimeout  int    `yaml:"read_timeout"`
 	WriteTimeout int    `yaml:"write_timeout"`
+	// AllowedOrigins 允许建立 WebSocket 连接的跨域页面 Origin，如 "http://localhost:8080"；同源页面始终允许
+	AllowedOrigins []string `yaml:"allowed_origins"`
 }
 
 // DatabaseConfig 数据库配置
@@ -56,6 +66,79 @@ type JWTConfig struct {
 	Expiration int `yaml:"expiration"` // 过期时间（小时）
 }
 
+// AuthConfig 静态 API Token 认证配置
+type AuthConfig struct {
+	Users []AuthUser `yaml:"users"`
+}
+
+// AuthUser 通过 Bearer Token 识别的用户及其角色（approver, admin）
+type AuthUser struct {
+	Name  string   `yaml:"name"`
+	Token string   `yaml:"token"`
+	Roles []string `yaml:"roles"`
+}
+
+// ArtifactsConfig 流水线制品存储配置
+type ArtifactsConfig struct {
+	Dir           string `yaml:"dir"`            // 制品存储目录
+	RetentionDays int    `yaml:"retention_days"` // 保留天数，0 表示永久保留
+	MaxSizeMB     int64  `yaml:"max_size_mb"`    // 单个上传文件大小上限
+}
+
+// GitConfig 本地 Git 镜像配置，用于生成变更日志
+type GitConfig struct {
+	WorkspaceDir string `yaml:"workspace_dir"` // 仓库镜像目录
+	Timeout      int    `yaml:"timeout"`       // 流水线开始时同步镜像并解析提交、结束时生成变更日志，各自的总超时（秒）
+	MaxCommits   int    `yaml:"max_commits"`   // 变更日志最多记录的提交数
+}
+
+// NotifyConfig 流水线通知配置
+type NotifyConfig struct {
+	BaseURL     string     `yaml:"base_url"`     // OpsGo 访问地址，用于通知和提交状态中的流水线链接
+	MaxAttempts int        `yaml:"max_attempts"` // 每个渠道（及提交状态回写）的最大发送次数
+	Backoff     int        `yaml:"backoff"`      // 首次重试等待（秒），之后逐次翻倍
+	SMTP        SMTPConfig `yaml:"smtp"`
+}
+
+// BroadcasterConfig 流水线实时事件分发配置
+type BroadcasterConfig struct {
+	Backend string `yaml:"backend"` // memory（单实例）或 redis（多实例共享）
+	Channel string `yaml:"channel"` // Redis Pub/Sub 频道
+}
+
+// LogsConfig 服务运行日志读取配置
+type LogsConfig struct {
+	Journalctl string `yaml:"journalctl"` // journald 日志源使用的 journalctl 路径
+}
+
+// PipelineLogConfig 部署脚本输出的规范化配置
+type PipelineLogConfig struct {
+	ANSI          string `yaml:"ansi"`            // spans（去除转义序列，颜色作为 spans 随实时日志事件发送）、strip（仅去除）或 keep（保留原样）
+	MaxLineLength int    `yaml:"max_line_length"` // 单行最大字节数，超出部分被截断
+}
+
+// RetentionConfig 流水线记录与日志的保留策略
+type RetentionConfig struct {
+	Enabled    bool   `yaml:"enabled"`      // 是否运行后台清理任务
+	DryRun     bool   `yaml:"dry_run"`      // 后台任务只统计，不修改数据
+	Interval   int    `yaml:"interval"`     // 后台任务间隔（分钟）
+	KeepLast   int    `yaml:"keep_last"`    // 每个服务在数据库中保留日志的最近流水线数，更早的日志压缩归档到磁盘，0 表示不归档
+	MaxAgeDays int    `yaml:"max_age_days"` // 超过该天数的流水线连同日志归档一起删除（每个服务最近 keep_last 条除外），0 表示不删除
+	ArchiveDir string `yaml:"archive_dir"`  // 日志归档目录
+	// Vacuum 非 dry run 归档或删除数据后对 SQLite 执行 VACUUM，把释放的空间还给文件系统；
+	// 期间数据库被锁定，数据量大时耗时较长。关闭时空闲页只在数据库内部复用
+	Vacuum bool `yaml:"vacuum"`
+}
+
+// SMTPConfig 邮件通知发件服务器
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
 var AppConfig *Config
 
 // LoadConfig 加载配置文件
@@ -128,6 +211,51 @@ func setDefaults() {
 	if AppConfig.JWT.SecretKey == "" {
 		AppConfig.JWT.SecretKey = "your-secret-key-change-in-production"
 	}
+	if AppConfig.Artifacts.Dir == "" {
+		AppConfig.Artifacts.Dir = "data/artifacts"
+	}
+	if AppConfig.Artifacts.MaxSizeMB == 0 {
+		AppConfig.Artifacts.MaxSizeMB = 512
+	}
+	if AppConfig.Git.WorkspaceDir == "" {
+		AppConfig.Git.WorkspaceDir = "data/repos"
+	}
+	if AppConfig.Git.Timeout == 0 {
+		AppConfig.Git.Timeout = 60
+	}
+	if AppConfig.Git.MaxCommits == 0 {
+		AppConfig.Git.MaxCommits = 200
+	}
+	if AppConfig.Notify.MaxAttempts == 0 {
+		AppConfig.Notify.MaxAttempts = 3
+	}
+	if AppConfig.Notify.Backoff == 0 {
+		AppConfig.Notify.Backoff = 5
+	}
+	if AppConfig.Notify.SMTP.Port == 0 {
+		AppConfig.Notify.SMTP.Port = 25
+	}
+	if AppConfig.Broadcaster.Backend == "" {
+		AppConfig.Broadcaster.Backend = "memory"
+	}
+	if AppConfig.Broadcaster.Channel == "" {
+		AppConfig.Broadcaster.Channel = "opsgo:devops:events"
+	}
+	if AppConfig.Logs.Journalctl == "" {
+		AppConfig.Logs.Journalctl = "journalctl"
+	}
+	if AppConfig.PipelineLog.ANSI == "" {
+		AppConfig.PipelineLog.ANSI = "spans"
+	}
+	if AppConfig.PipelineLog.MaxLineLength == 0 {
+		AppConfig.PipelineLog.MaxLineLength = 16 << 10
+	}
+	if AppConfig.Retention.Interval == 0 {
+		AppConfig.Retention.Interval = 60
+	}
+	if AppConfig.Retention.ArchiveDir == "" {
+		AppConfig.Retention.ArchiveDir = "data/archive"
+	}
 	if AppConfig.JWT.Expiration == 0 {
 		AppConfig.JWT.Expiration = 24 // 默认24小时
 	}
@@ -0,0 +1,117 @@
+// Package forge reports commit statuses to Git forges (GitHub, Gitea).
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultGitHubAPI = "https://api.github.com"
+
+// Commit status states understood by both GitHub and Gitea.
+const (
+	StatePending = "pending"
+	StateSuccess = "success"
+	StateFailure = "failure"
+	StateError   = "error"
+)
+
+// Status is a commit status as posted to the forge.
+type Status struct {
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context"`
+}
+
+// Client posts commit statuses with a personal or bot access token.
+type Client struct {
+	kind   string
+	apiURL string
+	token  string
+	http   *http.Client
+}
+
+// NewClient returns a client for kind "github" or "gitea". An empty apiURL
+// selects the public GitHub API.
+func NewClient(kind, apiURL, token string) (*Client, error) {
+	switch kind {
+	case "github":
+		if apiURL == "" {
+			apiURL = defaultGitHubAPI
+		}
+	case "gitea":
+		if apiURL == "" {
+			return nil, fmt.Errorf("api url is required for gitea")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported forge %q", kind)
+	}
+
+	return &Client{
+		kind:   kind,
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		token:  token,
+		http:   &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// SetCommitStatus creates a status on a commit of owner/repo. The HTTP status
+// code of the forge response is returned whenever a response was received.
+func (c *Client) SetCommitStatus(ctx context.Context, owner, repo, sha string, status Status) (int, error) {
+	body, err := json.Marshal(status)
+	if err != nil {
+		return 0, err
+	}
+
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/statuses/%s", c.apiURL, url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(sha))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		if c.kind == "github" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		} else {
+			req.Header.Set("Authorization", "token "+c.token)
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return resp.StatusCode, fmt.Errorf("forge returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp.StatusCode, nil
+}
+
+// ParseRepo extracts owner and repository name from an HTTPS or SSH clone URL,
+// e.g. https://github.com/acme/app.git or git@github.com:acme/app.git.
+func ParseRepo(repoURL string) (owner, repo string, err error) {
+	path := repoURL
+	if u, perr := url.Parse(repoURL); perr == nil && u.Scheme != "" && u.Host != "" {
+		path = u.Path
+	} else if i := strings.Index(repoURL, ":"); i >= 0 {
+		path = repoURL[i+1:] // scp-like syntax
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(strings.TrimRight(path, "/"), ".git"), "/"), "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", "", fmt.Errorf("cannot determine owner and repository from %q", repoURL)
+	}
+	return parts[len(parts)-2], parts[len(parts)-1], nil
+}
@@ -0,0 +1,119 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetCommitStatus(t *testing.T) {
+	tests := []struct {
+		kind     string
+		token    string
+		respond  int
+		wantAuth string
+		wantErr  bool
+	}{
+		{kind: "github", token: "ghp_abc", respond: 201, wantAuth: "Bearer ghp_abc"},
+		{kind: "gitea", token: "tea123", respond: 201, wantAuth: "token tea123"},
+		{kind: "gitea", respond: 201},
+		{kind: "github", token: "ghp_abc", respond: 422, wantAuth: "Bearer ghp_abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind+"/"+http.StatusText(tt.respond), func(t *testing.T) {
+			var gotPath, gotAuth string
+			var got Status
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotAuth = r.URL.EscapedPath(), r.Header.Get("Authorization")
+				body, _ := io.ReadAll(r.Body)
+				json.Unmarshal(body, &got)
+				w.WriteHeader(tt.respond)
+				io.WriteString(w, `{"message":"Validation Failed"}`)
+			}))
+			defer srv.Close()
+
+			client, err := NewClient(tt.kind, srv.URL+"/", tt.token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			status := Status{State: StateSuccess, TargetURL: "https://opsgo.example.com/pipelines/3", Context: "deployment"}
+			code, err := client.SetCommitStatus(context.Background(), "acme", "app", "0123abc", status)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetCommitStatus() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "Validation Failed") {
+				t.Errorf("error %q lacks the forge message", err)
+			}
+			if code != tt.respond {
+				t.Errorf("code = %d, want %d", code, tt.respond)
+			}
+			if gotPath != "/repos/acme/app/statuses/0123abc" {
+				t.Errorf("path = %q", gotPath)
+			}
+			if gotAuth != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", gotAuth, tt.wantAuth)
+			}
+			if got != status {
+				t.Errorf("posted %+v, want %+v", got, status)
+			}
+		})
+	}
+}
+
+func TestSetCommitStatusUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	client, _ := NewClient("gitea", srv.URL, "")
+	code, err := client.SetCommitStatus(context.Background(), "acme", "app", "0123abc", Status{State: StatePending})
+	if err == nil || code != 0 {
+		t.Errorf("SetCommitStatus() = %d, %v; want 0 and an error", code, err)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	if c, err := NewClient("github", "", "t"); err != nil || c.apiURL != defaultGitHubAPI {
+		t.Errorf("github without api url: %+v, %v", c, err)
+	}
+	if _, err := NewClient("gitea", "", "t"); err == nil {
+		t.Error("gitea without api url succeeded")
+	}
+	if _, err := NewClient("gitlab", "https://gitlab.example.com", "t"); err == nil {
+		t.Error("unsupported forge succeeded")
+	}
+}
+
+func TestParseRepo(t *testing.T) {
+	tests := []struct {
+		url       string
+		owner     string
+		repo      string
+		wantError bool
+	}{
+		{url: "https://github.com/acme/app.git", owner: "acme", repo: "app"},
+		{url: "https://github.com/acme/app", owner: "acme", repo: "app"},
+		{url: "https://gitea.example.com/git/acme/app.git/", owner: "acme", repo: "app"},
+		{url: "ssh://git@gitea.example.com:2222/acme/app.git", owner: "acme", repo: "app"},
+		{url: "git@github.com:acme/app.git", owner: "acme", repo: "app"},
+		{url: "git@github.com:acme/app", owner: "acme", repo: "app"},
+		{url: "https://github.com/app.git", wantError: true},
+		{url: "git@github.com:app.git", wantError: true},
+		{url: "", wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			owner, repo, err := ParseRepo(tt.url)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ParseRepo(%q) error = %v, want error %v", tt.url, err, tt.wantError)
+			}
+			if owner != tt.owner || repo != tt.repo {
+				t.Errorf("ParseRepo(%q) = %q, %q; want %q, %q", tt.url, owner, repo, tt.owner, tt.repo)
+			}
+		})
+	}
+}
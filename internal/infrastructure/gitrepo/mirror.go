@@ -0,0 +1,105 @@
+package gitrepo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mirror is a bare local mirror of a remote repository.
+type Mirror struct {
+	dir string
+	url string
+}
+
+func NewMirror(dir, url string) *Mirror {
+	return &Mirror{dir: dir, url: url}
+}
+
+// Commit is a single entry of a changelog.
+type Commit struct {
+	SHA     string    `json:"sha"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// Sync clones the mirror on first use and fetches all refs afterwards.
+func (m *Mirror) Sync(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(m.dir, "HEAD")); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(m.dir), 0755); err != nil {
+			return err
+		}
+		_, err := run(ctx, "", "clone", "--mirror", "--quiet", m.url, m.dir)
+		return err
+	}
+	_, err := m.git(ctx, "fetch", "--prune", "--quiet", "origin")
+	return err
+}
+
+// Resolve returns the commit SHA a ref, tag or SHA prefix points to.
+func (m *Mirror) Resolve(ctx context.Context, ref string) (string, error) {
+	out, err := m.git(ctx, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %q", ref)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Log lists the commits reachable from to but not from from, newest first.
+// An empty from lists the history of to. At most limit commits are returned.
+func (m *Mirror) Log(ctx context.Context, from, to string, limit int) ([]Commit, error) {
+	rng := to
+	if from != "" {
+		rng = from + ".." + to
+	}
+	out, err := m.git(ctx, "log", "--no-merges", "-n", strconv.Itoa(limit),
+		"--format=%H%x1f%an%x1f%ae%x1f%aI%x1f%s", rng, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []Commit{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[3])
+		commits = append(commits, Commit{
+			SHA:     fields[0],
+			Author:  fields[1],
+			Email:   fields[2],
+			Date:    date,
+			Subject: fields[4],
+		})
+	}
+	return commits, nil
+}
+
+func (m *Mirror) git(ctx context.Context, args ...string) (string, error) {
+	return run(ctx, m.dir, args...)
+}
+
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Never block on credential prompts; private repositories need a
+	// credential helper or SSH key configured for the OpsGo user.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
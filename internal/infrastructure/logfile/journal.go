@@ -0,0 +1,164 @@
+package logfile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Journal reads the systemd-journald entries of a unit with journalctl.
+type Journal struct {
+	Bin  string // journalctl binary
+	Unit string
+}
+
+type journalEntry struct {
+	Cursor   string          `json:"__CURSOR"`
+	Realtime string          `json:"__REALTIME_TIMESTAMP"` // microseconds since the epoch
+	Message  json.RawMessage `json:"MESSAGE"`
+}
+
+// line converts the entry; journald sends non UTF-8 messages as byte arrays.
+func (e *journalEntry) line() Line {
+	line := Line{Cursor: e.Cursor}
+	if usec, err := strconv.ParseInt(e.Realtime, 10, 64); err == nil {
+		t := time.UnixMicro(usec)
+		line.Time = &t
+	}
+	if err := json.Unmarshal(e.Message, &line.Text); err != nil {
+		var raw []byte
+		var ints []int
+		if json.Unmarshal(e.Message, &ints) == nil {
+			for _, b := range ints {
+				raw = append(raw, byte(b))
+			}
+		}
+		line.Text = string(raw)
+	}
+	line.Text = strings.TrimSuffix(line.Text, "\n")
+	return line
+}
+
+// Scan walks the entries before cursor (or from the newest one), newest
+// first, and calls fn with each entry the filter matches until fn returns
+// false.
+func (j *Journal) Scan(ctx context.Context, cursor string, filter Filter, fn func(Line) bool) error {
+	args := []string{"--reverse"}
+	if cursor != "" {
+		args = append(args, "--cursor="+cursor)
+	}
+	if filter.Since != nil {
+		args = append(args, "--since="+filter.Since.Local().Format(time.DateTime))
+	}
+	if filter.Until != nil {
+		args = append(args, "--until="+filter.Until.Local().Format(time.DateTime))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd, stdout, stderr, err := j.start(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	stopped := false
+	err = readEntries(stdout, func(line Line) bool {
+		if line.Cursor == cursor {
+			return true // --cursor starts at the entry already returned
+		}
+		if filter.Grep != nil && !filter.Grep.MatchString(line.Text) {
+			return true
+		}
+		stopped = !fn(line)
+		return !stopped
+	})
+	if stopped || err != nil {
+		cancel()
+		cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("journalctl: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Follow streams the last lines entries of the unit and then new ones, like
+// journalctl -f, until ctx is done.
+func (j *Journal) Follow(ctx context.Context, lines int) (<-chan Event, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	cmd, stdout, stderr, err := j.start(ctx, []string{"--follow", "--lines=" + strconv.Itoa(lines)})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	events := make(chan Event, 64)
+	send := func(event Event) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		defer close(events)
+		err := readEntries(stdout, func(line Line) bool {
+			return send(Event{Type: "line", Line: line.Text, Time: line.Time})
+		})
+		if err == nil && ctx.Err() == nil {
+			// journalctl exited by itself, e.g. for an unknown unit.
+			if err = cmd.Wait(); err != nil {
+				err = fmt.Errorf("journalctl: %v: %s", err, strings.TrimSpace(stderr.String()))
+			}
+		} else {
+			cancel()
+			cmd.Wait()
+		}
+		if err != nil {
+			send(Event{Type: "error", Line: err.Error()})
+		}
+		cancel()
+	}()
+	return events, nil
+}
+
+func (j *Journal) start(ctx context.Context, args []string) (*exec.Cmd, io.Reader, *bytes.Buffer, error) {
+	args = append([]string{"--unit=" + j.Unit, "--output=json", "--no-pager", "--quiet"}, args...)
+	cmd := exec.CommandContext(ctx, j.Bin, args...)
+	cmd.WaitDelay = time.Second
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to run journalctl: %v", err)
+	}
+	return cmd, stdout, stderr, nil
+}
+
+// readEntries decodes journalctl --output=json until fn returns false.
+func readEntries(r io.Reader, fn func(Line) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 4<<20)
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("invalid journalctl output: %v", err)
+		}
+		if !fn(entry.line()) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
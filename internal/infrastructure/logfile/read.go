@@ -0,0 +1,242 @@
+package logfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	maxGzipSize   = 256 << 20 // decompressed size limit of rotated logs
+	maxGroupLines = 10000     // untimestamped lines attributed to the line above them
+)
+
+// Line is a line of a log file.
+type Line struct {
+	File   string     `json:"file"`
+	Offset int64      `json:"offset"` // of the line start, in the uncompressed file
+	Time   *time.Time `json:"time,omitempty"`
+	Text   string     `json:"text"`
+	Cursor string     `json:"-"` // journald entries
+}
+
+// Filter selects lines. For Since and Until, lines without a timestamp of
+// their own, such as stack traces, have the time of the closest timestamped
+// line above them; lines without one, or more than maxGroupLines below it,
+// never match.
+type Filter struct {
+	Grep  *regexp.Regexp
+	Since *time.Time
+	Until *time.Time
+}
+
+// File is an opened log file, compressed ones being decompressed in memory.
+type File struct {
+	io.ReaderAt
+	Size  int64
+	close func() error
+}
+
+func (f *File) Close() error {
+	return f.close()
+}
+
+// Open opens a log file for Scan; files ending in .gz are decompressed.
+func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &File{ReaderAt: f, Size: info.Size(), close: f.Close}, nil
+	}
+
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	data, err := io.ReadAll(io.LimitReader(zr, maxGzipSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(data) > maxGzipSize {
+		return nil, fmt.Errorf("%s: decompressed size exceeds %d MB", path, maxGzipSize>>20)
+	}
+	return &File{ReaderAt: bytes.NewReader(data), Size: int64(len(data)), close: func() error { return nil }}, nil
+}
+
+// Siblings returns path followed by its rotated files, newest first, as
+// logrotate names them: app.log, app.log.1, app.log.2.gz, ...
+func Siblings(path string) []string {
+	files := []string{path}
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(name); err == nil {
+			files = append(files, name)
+		} else if _, err := os.Stat(name + ".gz"); err == nil {
+			files = append(files, name+".gz")
+		} else {
+			return files
+		}
+	}
+}
+
+// Scan walks the lines of f that start before offset before, newest first,
+// and calls fn with each line the filter matches until fn returns false. It
+// reports whether older lines, in f or in older files, may still match: false
+// once a line older than filter.Since was reached.
+func Scan(f *File, before int64, filter Filter, fn func(Line) bool) (bool, error) {
+	if before < 0 || before > f.Size {
+		before = f.Size
+	}
+	r := &backwardReader{r: f, pos: before, buf: make([]byte, readChunkSize)}
+	if filter.Since == nil && filter.Until == nil {
+		for {
+			offset, text, err := r.prev()
+			if err != nil {
+				return err == io.EOF, ignoreEOF(err)
+			}
+			line := Line{Offset: offset, Text: text}
+			if t, ok := ParseTimestamp(text); ok {
+				line.Time = &t
+			}
+			if (filter.Grep == nil || filter.Grep.MatchString(text)) && !fn(line) {
+				return true, nil
+			}
+		}
+	}
+
+	// Lines are collected, newest first, until the timestamped line above
+	// them gives them their time.
+	var group []Line
+	for {
+		offset, text, err := r.prev()
+		if err != nil {
+			return err == io.EOF, ignoreEOF(err)
+		}
+		group = append(group, Line{Offset: offset, Text: text})
+
+		t, ok := ParseTimestamp(text)
+		if !ok {
+			if len(group) > maxGroupLines {
+				group = group[:0]
+			}
+			continue
+		}
+		if filter.Since != nil && t.Before(*filter.Since) {
+			return false, nil
+		}
+		if filter.Until == nil || !t.After(*filter.Until) {
+			for _, line := range group {
+				line.Time = &t
+				if (filter.Grep == nil || filter.Grep.MatchString(line.Text)) && !fn(line) {
+					return true, nil
+				}
+			}
+		}
+		group = group[:0]
+	}
+}
+
+func ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// backwardReader reads lines from the end of a file towards its start.
+type backwardReader struct {
+	r       io.ReaderAt
+	pos     int64  // file offset of buf[0] once data is loaded
+	buf     []byte // scratch buffer for reads
+	pending []byte // read bytes before the lines already returned
+}
+
+// prev returns the line before the ones already returned, and its offset.
+func (b *backwardReader) prev() (int64, string, error) {
+	for {
+		// pending ends with the newline ending the line to return, if any.
+		end := len(b.pending)
+		if end > 0 && b.pending[end-1] == '\n' {
+			end--
+		}
+		if i := bytes.LastIndexByte(b.pending[:end], '\n'); i >= 0 {
+			text := string(b.pending[i+1 : end])
+			offset := b.pos + int64(i+1)
+			b.pending = b.pending[:i+1]
+			return offset, strings.TrimSuffix(text, "\r"), nil
+		}
+		if b.pos == 0 {
+			if len(b.pending) == 0 {
+				return 0, "", io.EOF
+			}
+			text := string(b.pending[:end])
+			b.pending = b.pending[:0]
+			return 0, strings.TrimSuffix(text, "\r"), nil
+		}
+
+		size := min(int64(len(b.buf)), b.pos)
+		b.pos -= size
+		if _, err := b.r.ReadAt(b.buf[:size], b.pos); err != nil && err != io.EOF {
+			return 0, "", err
+		}
+		b.pending = append(append(make([]byte, 0, int(size)+len(b.pending)), b.buf[:size]...), b.pending...)
+	}
+}
+
+// dateTimeLayouts are the two-field timestamps recognized at line start.
+var dateTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+}
+
+// ParseTimestamp parses the timestamp a log line starts with: RFC 3339,
+// "2006-01-02 15:04:05", "2006/01/02 15:04:05" (both with optional
+// fractional seconds, in local time) or the syslog "Jan _2 15:04:05".
+func ParseTimestamp(line string) (time.Time, bool) {
+	line = strings.TrimPrefix(line, "[")
+	fields := strings.Fields(line[:min(len(line), 40)])
+	if len(fields) == 0 {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339Nano, strings.TrimRight(fields[0], "]")); err == nil {
+		return t, true
+	}
+	if len(fields) < 2 {
+		return time.Time{}, false
+	}
+
+	value := strings.TrimRight(fields[0]+" "+fields[1], "]")
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+
+	if len(fields) < 3 {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(time.Stamp, strings.TrimRight(fields[0]+" "+fields[1]+" "+fields[2], "]"), time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	// Syslog has no year: the latest such time that is not in the future.
+	now := time.Now()
+	t = t.AddDate(now.Year(), 0, 0)
+	if t.After(now.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t, true
+}
@@ -0,0 +1,250 @@
+package logfile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	pollInterval  = 500 * time.Millisecond
+	readChunkSize = 32 << 10
+	maxLineLength = 64 << 10 // longer lines are sent in pieces
+)
+
+// Event is something that happened to a followed file.
+type Event struct {
+	Type string     `json:"type"` // line, truncated, rotated, error
+	File string     `json:"file,omitempty"`
+	Time *time.Time `json:"time,omitempty"` // journald entries
+	Line string     `json:"line,omitempty"`
+}
+
+// Follow streams the last lines of the file at path and then every line
+// appended to it, like tail -F: a truncated file is read again from the
+// start, and when the path is replaced by a new file (log rotation) the rest
+// of the old file is read before switching to the new one. A file that
+// doesn't exist yet is waited for and read from its start once created. The
+// channel is closed when ctx is done.
+func Follow(ctx context.Context, path string, lines int) (<-chan Event, error) {
+	t := &tailer{
+		path:   path,
+		events: make(chan Event, 64),
+		buf:    make([]byte, readChunkSize),
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		go t.run(ctx)
+		return t.events, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	offset, err := LastLines(f, lines)
+	if err == nil {
+		_, err = f.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	t.file, t.offset = f, offset
+	go t.run(ctx)
+	return t.events, nil
+}
+
+// FollowAll follows several files at once; events carry the file name.
+func FollowAll(ctx context.Context, paths []string, lines int) (<-chan Event, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	merged := make(chan Event, 64)
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		events, err := Follow(ctx, path, lines)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for event := range events {
+				select {
+				case merged <- event:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(merged)
+	}()
+	return merged, nil
+}
+
+type tailer struct {
+	path    string
+	file    *os.File // nil until the path exists
+	offset  int64
+	partial []byte // read bytes not yet ended by a newline
+	events  chan Event
+	buf     []byte
+}
+
+func (t *tailer) run(ctx context.Context) {
+	defer close(t.events)
+	defer func() {
+		if t.file != nil {
+			t.file.Close()
+		}
+	}()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if t.file == nil {
+			// Missing until now, so every line in it is new.
+			if f, err := os.Open(t.path); err == nil {
+				t.file = f
+			}
+		}
+		if t.file != nil && (!t.readAvailable(ctx) || !t.checkFile(ctx)) {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// readAvailable sends the complete lines appended since the last read.
+func (t *tailer) readAvailable(ctx context.Context) bool {
+	for {
+		n, err := t.file.Read(t.buf)
+		t.offset += int64(n)
+		t.partial = append(t.partial, t.buf[:n]...)
+		for {
+			i := bytes.IndexByte(t.partial, '\n')
+			if i < 0 {
+				break
+			}
+			if !t.send(ctx, Event{Type: "line", Line: string(t.partial[:i])}) {
+				return false
+			}
+			t.partial = t.partial[i+1:]
+		}
+		if len(t.partial) >= maxLineLength {
+			if !t.flush(ctx) {
+				return false
+			}
+		}
+
+		if err == io.EOF || n == 0 {
+			return true
+		}
+		if err != nil {
+			return t.send(ctx, Event{Type: "error", Line: err.Error()})
+		}
+	}
+}
+
+// checkFile detects truncation and rotation of the followed path. A path that
+// is missing for a moment during rotation keeps the old file open.
+func (t *tailer) checkFile(ctx context.Context) bool {
+	current, err := t.file.Stat()
+	if err != nil {
+		return t.send(ctx, Event{Type: "error", Line: err.Error()})
+	}
+	if current.Size() < t.offset {
+		t.partial = nil
+		t.offset = 0
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return t.send(ctx, Event{Type: "error", Line: err.Error()})
+		}
+		return t.send(ctx, Event{Type: "truncated"})
+	}
+
+	latest, err := os.Stat(t.path)
+	if err != nil || os.SameFile(current, latest) {
+		return true
+	}
+	next, err := os.Open(t.path)
+	if err != nil {
+		return true // retried on the next poll
+	}
+
+	// Lines written to the old file after the last read belong before the
+	// ones of the new file.
+	if !t.readAvailable(ctx) || !t.flush(ctx) {
+		next.Close()
+		return false
+	}
+	t.file.Close()
+	t.file = next
+	t.offset = 0
+	return t.send(ctx, Event{Type: "rotated"})
+}
+
+// flush sends an unterminated line as it is.
+func (t *tailer) flush(ctx context.Context) bool {
+	if len(t.partial) == 0 {
+		return true
+	}
+	line := string(t.partial)
+	t.partial = nil
+	return t.send(ctx, Event{Type: "line", Line: line})
+}
+
+func (t *tailer) send(ctx context.Context, event Event) bool {
+	event.File = filepath.Base(t.path)
+	select {
+	case t.events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// LastLines returns the offset at which the last n lines of f start.
+func LastLines(f *os.File, n int) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	end := info.Size()
+	if n <= 0 {
+		return end, nil
+	}
+
+	buf := make([]byte, readChunkSize)
+	pos := end
+	for pos > 0 {
+		size := min(int64(len(buf)), pos)
+		pos -= size
+		if _, err := f.ReadAt(buf[:size], pos); err != nil && err != io.EOF {
+			return 0, err
+		}
+		for i := size - 1; i >= 0; i-- {
+			// A final newline ends the last line, it doesn't start one.
+			if buf[i] != '\n' || pos+i == end-1 {
+				continue
+			}
+			if n--; n == 0 {
+				return pos + i + 1, nil
+			}
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,136 @@
+package logfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expectEvents reads events and compares them, lines as "line <text>" and
+// other events by type.
+func expectEvents(t *testing.T, events <-chan Event, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("events closed, want %q", w)
+			}
+			got := event.Type
+			if event.Type == "line" || event.Type == "error" {
+				got += " " + event.Line
+			}
+			if got != w {
+				t.Fatalf("got %q, want %q", got, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no event, want %q", w)
+		}
+	}
+}
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "one\ntwo\nthree\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := Follow(ctx, path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectEvents(t, events, "line two", "line three")
+
+	appendFile(t, path, "four\nfi")
+	expectEvents(t, events, "line four")
+	appendFile(t, path, "ve\n")
+	expectEvents(t, events, "line five")
+
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	expectEvents(t, events, "truncated")
+	appendFile(t, path, "six\n")
+	expectEvents(t, events, "line six")
+
+	// Rotation: the rest of the old file comes before the new one.
+	appendFile(t, path, "seven\n")
+	expectEvents(t, events, "line seven")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path+".1", "eight\n")
+	appendFile(t, path, "nine\n")
+	expectEvents(t, events, "line eight", "rotated", "line nine")
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestFollowWaitsForFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := Follow(ctx, path, 10)
+	if err != nil {
+		t.Fatalf("Follow() of a missing file: %v", err)
+	}
+	time.Sleep(pollInterval)
+	appendFile(t, path, "first\nsecond\n")
+	expectEvents(t, events, "line first", "line second")
+
+	if _, err := Follow(ctx, t.TempDir(), 10); err == nil {
+		t.Error("Follow() of a directory succeeded")
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	tests := []struct {
+		content string
+		n       int
+		want    string
+	}{
+		{"a\nb\nc\n", 2, "b\nc\n"},
+		{"a\nb\nc", 2, "b\nc"},
+		{"a\nb\nc\n", 5, "a\nb\nc\n"},
+		{"a\nb\nc\n", 0, ""},
+		{"", 3, ""},
+		{"\n\n", 1, "\n"},
+		{strings.Repeat("x\n", readChunkSize) + "last\n", 1, "last\n"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "app.log")
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		offset, err := LastLines(f, tt.n)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tt.content[offset:]; got != tt.want {
+			t.Errorf("LastLines(%.20q, %d) starts at %q, want %q", tt.content, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Slack posts to a Slack-compatible incoming webhook (also Mattermost, Rocket.Chat).
+type Slack struct {
+	url string
+}
+
+func NewSlack(url string) *Slack {
+	return &Slack{url: url}
+}
+
+func (s *Slack) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(map[string]string{"text": msg.Text()})
+	if err != nil {
+		return err
+	}
+	_, err = postJSON(ctx, s.url, body, nil)
+	return err
+}
+
+// DingTalk posts to a DingTalk custom robot. The secret enables the robot's
+// "加签" signature check.
+type DingTalk struct {
+	url    string
+	secret string
+}
+
+func NewDingTalk(url, secret string) *DingTalk {
+	return &DingTalk{url: url, secret: secret}
+}
+
+func (d *DingTalk) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": msg.Title(),
+			"text":  strings.ReplaceAll(msg.Text(), "\n", "\n\n"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	target := d.url
+	if d.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(d.secret))
+		mac.Write([]byte(timestamp + "\n" + d.secret))
+		sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += fmt.Sprintf("%stimestamp=%s&sign=%s", sep, timestamp, url.QueryEscape(sign))
+	}
+
+	resp, err := postJSON(ctx, target, body, nil)
+	if err != nil {
+		return err
+	}
+	return checkBotResponse(resp)
+}
+
+// Feishu posts to a Feishu/Lark custom bot, signing the request when a
+// secret is configured.
+type Feishu struct {
+	url    string
+	secret string
+}
+
+func NewFeishu(url, secret string) *Feishu {
+	return &Feishu{url: url, secret: secret}
+}
+
+func (f *Feishu) Send(ctx context.Context, msg Message) error {
+	payload := map[string]interface{}{
+		"msg_type": "text",
+		"content":  map[string]string{"text": msg.Text()},
+	}
+	if f.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		// Feishu uses the string to sign as the HMAC key over an empty message.
+		mac := hmac.New(sha256.New, []byte(timestamp+"\n"+f.secret))
+		payload["timestamp"] = timestamp
+		payload["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := postJSON(ctx, f.url, body, nil)
+	if err != nil {
+		return err
+	}
+	return checkBotResponse(resp)
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPSettings is the outgoing mail server shared by email channels.
+type SMTPSettings struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Email sends the message as a plain text mail to a list of recipients.
+type Email struct {
+	smtp       SMTPSettings
+	recipients []string
+}
+
+func NewEmail(settings SMTPSettings, recipients []string) *Email {
+	return &Email{smtp: settings, recipients: recipients}
+}
+
+func (e *Email) Send(ctx context.Context, msg Message) error {
+	if e.smtp.Host == "" {
+		return fmt.Errorf("smtp host is not configured")
+	}
+	if len(e.recipients) == 0 {
+		return fmt.Errorf("email channel has no recipients")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.smtp.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.recipients, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Title()))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Text(), "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if e.smtp.Username != "" {
+		auth = smtp.PlainAuth("", e.smtp.Username, e.smtp.Password, e.smtp.Host)
+	}
+	addr := net.JoinHostPort(e.smtp.Host, fmt.Sprint(e.smtp.Port))
+
+	// smtp.SendMail has no context support; run it aside so a hung server
+	// does not outlive the delivery deadline.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, e.smtp.From, e.recipients, []byte(b.String()))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
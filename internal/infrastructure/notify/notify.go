@@ -0,0 +1,131 @@
+// Package notify delivers pipeline notifications to chat tools, webhooks and email.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Message is a pipeline status change as delivered to every channel.
+type Message struct {
+	Event       string    `json:"event"` // pipeline.<status>, or notification.test
+	PipelineID  uint64    `json:"pipeline_id"`
+	ServiceID   uint64    `json:"service_id"`
+	Service     string    `json:"service"`
+	Status      string    `json:"status"`
+	Ref         string    `json:"ref"`
+	CommitSHA   string    `json:"commit_sha"`
+	TriggeredBy string    `json:"triggered_by"`
+	Source      string    `json:"trigger_source"`
+	Duration    int64     `json:"duration"` // seconds
+	Link        string    `json:"link,omitempty"`
+	Changelog   []Commit  `json:"changelog"`
+	Time        time.Time `json:"time"`
+	Body        string    `json:"-"` // rendered channel template, replaces the default text
+}
+
+// Commit is a changelog entry of the deployed release.
+type Commit struct {
+	SHA     string `json:"sha"`
+	Author  string `json:"author"`
+	Subject string `json:"subject"`
+}
+
+// Sender delivers a message to one channel.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+const maxChangelogLines = 10
+
+// Title is a one-line summary of the message.
+func (m Message) Title() string {
+	return fmt.Sprintf("[OpsGo] %s #%d %s", m.Service, m.PipelineID, strings.ToUpper(m.Status))
+}
+
+// Text renders the message as plain text for chat and email channels.
+func (m Message) Text() string {
+	if m.Body != "" {
+		return m.Body
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", m.Title())
+	fmt.Fprintf(&b, "Ref: %s", m.Ref)
+	if m.CommitSHA != "" {
+		fmt.Fprintf(&b, " (%.7s)", m.CommitSHA)
+	}
+	b.WriteString("\n")
+	if m.TriggeredBy != "" {
+		fmt.Fprintf(&b, "Triggered by: %s (%s)\n", m.TriggeredBy, m.Source)
+	}
+	if m.Duration > 0 {
+		fmt.Fprintf(&b, "Duration: %ds\n", m.Duration)
+	}
+	if len(m.Changelog) > 0 {
+		fmt.Fprintf(&b, "Changes (%d):\n", len(m.Changelog))
+		for i, c := range m.Changelog {
+			if i == maxChangelogLines {
+				fmt.Fprintf(&b, "- ... and %d more\n", len(m.Changelog)-i)
+				break
+			}
+			fmt.Fprintf(&b, "- %.7s %s (%s)\n", c.SHA, c.Subject, c.Author)
+		}
+	}
+	if m.Link != "" {
+		fmt.Fprintf(&b, "%s\n", m.Link)
+	}
+	return b.String()
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// postJSON posts body and treats any non-2xx response as a failure.
+func postJSON(ctx context.Context, url string, body []byte, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
+
+// checkBotResponse reports errors that DingTalk and Feishu return with HTTP 200.
+func checkBotResponse(body []byte) error {
+	var result struct {
+		ErrCode *int   `json:"errcode"` // DingTalk
+		ErrMsg  string `json:"errmsg"`
+		Code    *int   `json:"code"` // Feishu
+		Msg     string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil
+	}
+	if result.ErrCode != nil && *result.ErrCode != 0 {
+		return fmt.Errorf("bot error %d: %s", *result.ErrCode, result.ErrMsg)
+	}
+	if result.Code != nil && *result.Code != 0 {
+		return fmt.Errorf("bot error %d: %s", *result.Code, result.Msg)
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testMessage = Message{
+	Event:      "pipeline.success",
+	PipelineID: 12,
+	ServiceID:  3,
+	Service:    "app",
+	Status:     "success",
+	Ref:        "v1.2.0",
+	CommitSHA:  "0123456789abcdef",
+	Time:       time.Unix(1700000000, 0).UTC(),
+}
+
+// request is what a stand-in server received.
+type request struct {
+	header http.Header
+	query  map[string]string
+	body   []byte
+}
+
+// standIn serves the given response and records the requests it received.
+func standIn(t *testing.T, status int, response string) (*httptest.Server, <-chan request) {
+	t.Helper()
+	received := make(chan request, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		query := map[string]string{}
+		for k := range r.URL.Query() {
+			query[k] = r.URL.Query().Get(k)
+		}
+		received <- request{header: r.Header, query: query, body: body}
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, received
+}
+
+func TestWebhook(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		body      string // rendered template
+		wantBody  string
+		wantError bool
+		status    int
+	}{
+		{name: "unsigned", status: 200},
+		{name: "signed", secret: "s3cret", status: 200},
+		{name: "template", secret: "s3cret", body: "app deployed", wantBody: "app deployed", status: 200},
+		{name: "server error", status: 502, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, received := standIn(t, tt.status, "")
+			msg := testMessage
+			msg.Body = tt.body
+
+			err := NewWebhook(srv.URL, tt.secret).Send(context.Background(), msg)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("Send() error = %v, want error %v", err, tt.wantError)
+			}
+
+			r := <-received
+			if got := r.header.Get("X-OpsGo-Event"); got != "pipeline.success" {
+				t.Errorf("X-OpsGo-Event = %q", got)
+			}
+			if tt.wantBody != "" {
+				if string(r.body) != tt.wantBody {
+					t.Errorf("body = %q, want %q", r.body, tt.wantBody)
+				}
+			} else {
+				var got Message
+				if err := json.Unmarshal(r.body, &got); err != nil || got.PipelineID != 12 || got.Status != "success" {
+					t.Errorf("body = %s (%v)", r.body, err)
+				}
+			}
+
+			signature := r.header.Get("X-OpsGo-Signature")
+			if tt.secret == "" {
+				if signature != "" {
+					t.Errorf("unsigned webhook has X-OpsGo-Signature %q", signature)
+				}
+				return
+			}
+			mac := hmac.New(sha256.New, []byte(tt.secret))
+			mac.Write(r.body)
+			if want := fmt.Sprintf("sha256=%x", mac.Sum(nil)); signature != want {
+				t.Errorf("X-OpsGo-Signature = %q, want %q", signature, want)
+			}
+		})
+	}
+}
+
+func TestDingTalk(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		response  string
+		wantError bool
+	}{
+		{name: "unsigned", response: `{"errcode":0,"errmsg":"ok"}`},
+		{name: "signed", secret: "SEC123", response: `{"errcode":0,"errmsg":"ok"}`},
+		{name: "errcode", secret: "SEC123", response: `{"errcode":310000,"errmsg":"sign not match"}`, wantError: true},
+		{name: "not json", response: `ok`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, received := standIn(t, 200, tt.response)
+
+			err := NewDingTalk(srv.URL+"/robot/send?access_token=abc", tt.secret).Send(context.Background(), testMessage)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("Send() error = %v, want error %v", err, tt.wantError)
+			}
+			if tt.wantError && !strings.Contains(err.Error(), "310000") {
+				t.Errorf("error %q lacks the errcode", err)
+			}
+
+			r := <-received
+			if r.query["access_token"] != "abc" {
+				t.Errorf("access_token = %q", r.query["access_token"])
+			}
+			var payload struct {
+				MsgType  string `json:"msgtype"`
+				Markdown struct {
+					Title string `json:"title"`
+					Text  string `json:"text"`
+				} `json:"markdown"`
+			}
+			if err := json.Unmarshal(r.body, &payload); err != nil || payload.MsgType != "markdown" || payload.Markdown.Title != testMessage.Title() {
+				t.Errorf("payload = %s (%v)", r.body, err)
+			}
+
+			if tt.secret == "" {
+				if _, ok := r.query["sign"]; ok {
+					t.Error("unsigned request has a sign")
+				}
+				return
+			}
+			mac := hmac.New(sha256.New, []byte(tt.secret))
+			mac.Write([]byte(r.query["timestamp"] + "\n" + tt.secret))
+			if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); r.query["sign"] != want {
+				t.Errorf("sign = %q, want %q", r.query["sign"], want)
+			}
+			checkTimestamp(t, r.query["timestamp"], time.Millisecond)
+		})
+	}
+}
+
+func TestFeishu(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		response  string
+		wantError bool
+	}{
+		{name: "unsigned", response: `{"code":0,"msg":"success"}`},
+		{name: "signed", secret: "fs-secret", response: `{"code":0,"msg":"success"}`},
+		{name: "code", secret: "fs-secret", response: `{"code":19021,"msg":"sign match fail or timestamp is not within one hour from current time"}`, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, received := standIn(t, 200, tt.response)
+
+			err := NewFeishu(srv.URL, tt.secret).Send(context.Background(), testMessage)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("Send() error = %v, want error %v", err, tt.wantError)
+			}
+			if tt.wantError && !strings.Contains(err.Error(), "19021") {
+				t.Errorf("error %q lacks the code", err)
+			}
+
+			r := <-received
+			var payload struct {
+				MsgType   string            `json:"msg_type"`
+				Content   map[string]string `json:"content"`
+				Timestamp string            `json:"timestamp"`
+				Sign      string            `json:"sign"`
+			}
+			if err := json.Unmarshal(r.body, &payload); err != nil || payload.MsgType != "text" || payload.Content["text"] != testMessage.Text() {
+				t.Fatalf("payload = %s (%v)", r.body, err)
+			}
+
+			if tt.secret == "" {
+				if payload.Sign != "" || payload.Timestamp != "" {
+					t.Error("unsigned request has a sign")
+				}
+				return
+			}
+			mac := hmac.New(sha256.New, []byte(payload.Timestamp+"\n"+tt.secret))
+			if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); payload.Sign != want {
+				t.Errorf("sign = %q, want %q", payload.Sign, want)
+			}
+			checkTimestamp(t, payload.Timestamp, time.Second)
+		})
+	}
+}
+
+func checkTimestamp(t *testing.T, value string, unit time.Duration) {
+	t.Helper()
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp %q: %v", value, err)
+	}
+	if d := time.Since(time.Unix(0, n*int64(unit))); d < 0 || d > time.Minute {
+		t.Errorf("timestamp %q is %s off", value, d)
+	}
+}
+
+func TestSlackServerError(t *testing.T) {
+	srv, received := standIn(t, 404, "no_service")
+	err := NewSlack(srv.URL).Send(context.Background(), testMessage)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Send() error = %v, want the 404", err)
+	}
+	r := <-received
+	var payload map[string]string
+	if err := json.Unmarshal(r.body, &payload); err != nil || payload["text"] != testMessage.Text() {
+		t.Errorf("payload = %s (%v)", r.body, err)
+	}
+}
+
+func TestEmail(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	mail := make(chan string, 1)
+	go serveSMTP(ln, mail)
+
+	addr := ln.Addr().(*net.TCPAddr)
+	email := NewEmail(SMTPSettings{Host: "127.0.0.1", Port: addr.Port, From: "opsgo@example.com"}, []string{"ops@example.com", "dev@example.com"})
+	if err := email.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	got := <-mail
+	for _, want := range []string{
+		"MAIL FROM:<opsgo@example.com>",
+		"RCPT TO:<ops@example.com>",
+		"RCPT TO:<dev@example.com>",
+		"To: ops@example.com, dev@example.com\r\n",
+		"Subject: [OpsGo] app #12 SUCCESS\r\n",
+		"Ref: v1.2.0 (0123456)\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("mail lacks %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestEmailWithoutServer(t *testing.T) {
+	if err := NewEmail(SMTPSettings{}, []string{"ops@example.com"}).Send(context.Background(), testMessage); err == nil {
+		t.Error("Send() without smtp host succeeded")
+	}
+}
+
+// serveSMTP accepts one mail and sends the whole session to mail.
+func serveSMTP(ln net.Listener, mail chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var session strings.Builder
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+	reply("220 stand-in ESMTP")
+	data := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		session.WriteString(line)
+		switch {
+		case data:
+			if line == ".\r\n" {
+				data = false
+				reply("250 queued")
+			}
+		case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+			reply("250 stand-in")
+		case strings.HasPrefix(line, "DATA"):
+			data = true
+			reply("354 go ahead")
+		case strings.HasPrefix(line, "QUIT"):
+			reply("221 bye")
+			mail <- session.String()
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
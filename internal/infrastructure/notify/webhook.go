@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+)
+
+// Webhook posts the message as JSON, or the rendered template as is when the
+// channel has one. With a secret, the body is signed with HMAC-SHA256 in the
+// X-OpsGo-Signature header as "sha256=<hex>".
+type Webhook struct {
+	url    string
+	secret string
+}
+
+func NewWebhook(url, secret string) *Webhook {
+	return &Webhook{url: url, secret: secret}
+}
+
+func (w *Webhook) Send(ctx context.Context, msg Message) error {
+	body := []byte(msg.Body)
+	if msg.Body == "" {
+		var err error
+		if body, err = json.Marshal(msg); err != nil {
+			return err
+		}
+	}
+
+	header := http.Header{}
+	header.Set("X-OpsGo-Event", msg.Event)
+	if w.secret != "" {
+		header.Set("X-OpsGo-Signature", "sha256="+Sign(w.secret, body))
+	}
+	_, err := postJSON(ctx, w.url, body, header)
+	return err
+}
+
+// Sign returns the hex HMAC-SHA256 of body, for receivers verifying webhooks.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -4,6 +4,7 @@ import (
 	"OpsGo/internal/domain/entity/devops"
 	"OpsGo/internal/domain/repository"
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -16,6 +17,16 @@ func NewDevOpsRepository(db *gorm.DB) repository.DevOpsRepository {
 	return &devopsRepository{db: db}
 }
 
+// MigratePipelineLogs moves the logs of pipelines recorded before they had
+// their own column out of commit_msg, where they were stored, and returns how
+// many pipelines it moved.
+func MigratePipelineLogs(db *gorm.DB) (int64, error) {
+	result := db.Model(&devops.PipelineRecord{}).
+		Where("coalesce(log, '') = '' AND coalesce(log_archive, '') = '' AND coalesce(commit_msg, '') <> ''").
+		Updates(map[string]interface{}{"log": gorm.Expr("commit_msg"), "commit_msg": ""})
+	return result.RowsAffected, result.Error
+}
+
 func (r *devopsRepository) SaveConfig(ctx context.Context, config *devops.RepoConfig) error {
 	return r.db.WithContext(ctx).Save(config).Error
 }
@@ -62,8 +73,285 @@ func (r *devopsRepository) GetPipelineRecord(ctx context.Context, id uint64) *de
 	return &record
 }
 
+// ListPipelineRecords returns the latest records, without their logs.
 func (r *devopsRepository) ListPipelineRecords(ctx context.Context, limit int) ([]devops.PipelineRecord, error) {
 	var records []devops.PipelineRecord
-	err := r.db.WithContext(ctx).Order("id desc").Limit(limit).Find(&records).Error
+	err := r.db.WithContext(ctx).Omit("log", "log_marks").Order("id desc").Limit(limit).Find(&records).Error
+	return records, err
+}
+
+// ListPipelineRecordsPage returns one page of matching records, without their
+// logs, and the total number of matches.
+func (r *devopsRepository) ListPipelineRecordsPage(ctx context.Context, filter repository.PipelineFilter) ([]devops.PipelineRecord, int64, error) {
+	query := r.db.WithContext(ctx).Model(&devops.PipelineRecord{})
+	if filter.ConfigID != 0 {
+		query = query.Where("config_id = ?", filter.ConfigID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.TriggerSource != "" {
+		query = query.Where("trigger_source = ?", filter.TriggerSource)
+	}
+	if filter.Ref != "" {
+		query = query.Where("ref = ?", filter.Ref)
+	}
+	if filter.Author != "" {
+		query = query.Where("author = ?", filter.Author)
+	}
+	// Timestamps are stored in server local time, compare in the same zone.
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", filter.From.Local())
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", filter.To.Local())
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = "id"
+	}
+	order := sortBy + " asc"
+	if filter.SortDesc {
+		order = sortBy + " desc"
+	}
+
+	var records []devops.PipelineRecord
+	err := query.Omit("log", "log_marks").Order(order).Order("id desc").Offset(filter.Offset).Limit(filter.Limit).Find(&records).Error
+	return records, total, err
+}
+
+func (r *devopsRepository) ListPipelineRecordsByStatus(ctx context.Context, status string) ([]devops.PipelineRecord, error) {
+	var records []devops.PipelineRecord
+	err := r.db.WithContext(ctx).Where("status = ?", status).Order("id asc").Find(&records).Error
 	return records, err
 }
+
+func (r *devopsRepository) GetLastSuccessfulPipeline(ctx context.Context, configID uint64, beforeID uint64) *devops.PipelineRecord {
+	var record devops.PipelineRecord
+	err := r.db.WithContext(ctx).
+		Where("config_id = ? AND status = ? AND id < ?", configID, "success", beforeID).
+		Order("id desc").
+		First(&record).Error
+	if err != nil {
+		return nil
+	}
+	return &record
+}
+
+func (r *devopsRepository) ClaimPipelineStatus(ctx context.Context, id uint64, from, to string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&devops.PipelineRecord{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *devopsRepository) CreatePipelineAttempt(ctx context.Context, attempt *devops.PipelineAttempt) error {
+	return r.db.WithContext(ctx).Create(attempt).Error
+}
+
+func (r *devopsRepository) ListPipelineAttempts(ctx context.Context, pipelineID uint64) ([]devops.PipelineAttempt, error) {
+	var attempts []devops.PipelineAttempt
+	err := r.db.WithContext(ctx).Where("pipeline_id = ?", pipelineID).Order("attempt asc").Find(&attempts).Error
+	return attempts, err
+}
+
+func (r *devopsRepository) SaveFreezeWindow(ctx context.Context, window *devops.FreezeWindow) error {
+	return r.db.WithContext(ctx).Save(window).Error
+}
+
+func (r *devopsRepository) ListFreezeWindows(ctx context.Context) ([]devops.FreezeWindow, error) {
+	var windows []devops.FreezeWindow
+	err := r.db.WithContext(ctx).Order("id desc").Find(&windows).Error
+	return windows, err
+}
+
+// ListActiveFreezeWindows returns the enabled global windows and those of configID.
+func (r *devopsRepository) ListActiveFreezeWindows(ctx context.Context, configID uint64) ([]devops.FreezeWindow, error) {
+	var windows []devops.FreezeWindow
+	err := r.db.WithContext(ctx).
+		Where("enabled = ? AND config_id IN ?", true, []uint64{0, configID}).
+		Order("id asc").
+		Find(&windows).Error
+	return windows, err
+}
+
+func (r *devopsRepository) DeleteFreezeWindow(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Delete(&devops.FreezeWindow{}, id).Error
+}
+
+func (r *devopsRepository) SaveSchedule(ctx context.Context, schedule *devops.DeploySchedule) error {
+	return r.db.WithContext(ctx).Save(schedule).Error
+}
+
+func (r *devopsRepository) GetSchedule(ctx context.Context, id uint64) *devops.DeploySchedule {
+	var schedule devops.DeploySchedule
+	if err := r.db.WithContext(ctx).First(&schedule, id).Error; err != nil {
+		return nil
+	}
+	return &schedule
+}
+
+func (r *devopsRepository) ListSchedules(ctx context.Context) ([]devops.DeploySchedule, error) {
+	var schedules []devops.DeploySchedule
+	err := r.db.WithContext(ctx).Order("id desc").Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *devopsRepository) ListDueSchedules(ctx context.Context, now time.Time) ([]devops.DeploySchedule, error) {
+	var schedules []devops.DeploySchedule
+	err := r.db.WithContext(ctx).
+		Where("paused = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", false, now).
+		Order("next_run_at asc").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *devopsRepository) ClaimScheduleRun(ctx context.Context, id uint64, due time.Time, next *time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&devops.DeploySchedule{}).
+		Where("id = ? AND paused = ? AND next_run_at = ?", id, false, due).
+		Update("next_run_at", next)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *devopsRepository) SaveScheduleRun(ctx context.Context, schedule *devops.DeploySchedule) error {
+	return r.db.WithContext(ctx).Model(schedule).
+		Select("last_run_at", "last_pipeline_id", "last_error").
+		Updates(schedule).Error
+}
+
+func (r *devopsRepository) DeleteSchedule(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Delete(&devops.DeploySchedule{}, id).Error
+}
+
+func (r *devopsRepository) SaveArtifact(ctx context.Context, artifact *devops.PipelineArtifact) error {
+	return r.db.WithContext(ctx).Save(artifact).Error
+}
+
+func (r *devopsRepository) GetArtifact(ctx context.Context, id uint64) *devops.PipelineArtifact {
+	var artifact devops.PipelineArtifact
+	if err := r.db.WithContext(ctx).First(&artifact, id).Error; err != nil {
+		return nil
+	}
+	return &artifact
+}
+
+func (r *devopsRepository) GetArtifactByName(ctx context.Context, pipelineID uint64, name string) *devops.PipelineArtifact {
+	var artifact devops.PipelineArtifact
+	if err := r.db.WithContext(ctx).Where("pipeline_id = ? AND name = ?", pipelineID, name).First(&artifact).Error; err != nil {
+		return nil
+	}
+	return &artifact
+}
+
+func (r *devopsRepository) ListArtifacts(ctx context.Context, pipelineID uint64) ([]devops.PipelineArtifact, error) {
+	var artifacts []devops.PipelineArtifact
+	err := r.db.WithContext(ctx).Where("pipeline_id = ?", pipelineID).Order("name asc").Find(&artifacts).Error
+	return artifacts, err
+}
+
+func (r *devopsRepository) ListExpiredArtifacts(ctx context.Context, now time.Time) ([]devops.PipelineArtifact, error) {
+	var artifacts []devops.PipelineArtifact
+	err := r.db.WithContext(ctx).
+		Where("expires_at IS NOT NULL AND expires_at <= ?", now.UTC()).
+		Order("id asc").
+		Find(&artifacts).Error
+	return artifacts, err
+}
+
+func (r *devopsRepository) DeleteArtifact(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Delete(&devops.PipelineArtifact{}, id).Error
+}
+
+func (r *devopsRepository) ActivateRelease(ctx context.Context, release *devops.Release) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&devops.Release{}).
+			Where("config_id = ? AND active = ?", release.ConfigID, true).
+			Updates(map[string]interface{}{"active": false, "retired_at": release.DeployedAt}).Error
+		if err != nil {
+			return err
+		}
+		release.Active = true
+		return tx.Create(release).Error
+	})
+}
+
+func (r *devopsRepository) GetActiveRelease(ctx context.Context, configID uint64) *devops.Release {
+	var release devops.Release
+	if err := r.db.WithContext(ctx).Where("config_id = ? AND active = ?", configID, true).First(&release).Error; err != nil {
+		return nil
+	}
+	return &release
+}
+
+func (r *devopsRepository) ListReleases(ctx context.Context, configID uint64) ([]devops.Release, error) {
+	var releases []devops.Release
+	err := r.db.WithContext(ctx).Where("config_id = ?", configID).Order("id desc").Find(&releases).Error
+	return releases, err
+}
+
+func (r *devopsRepository) SaveNotificationChannel(ctx context.Context, channel *devops.NotificationChannel) error {
+	return r.db.WithContext(ctx).Save(channel).Error
+}
+
+func (r *devopsRepository) GetNotificationChannel(ctx context.Context, id uint64) *devops.NotificationChannel {
+	var channel devops.NotificationChannel
+	if err := r.db.WithContext(ctx).First(&channel, id).Error; err != nil {
+		return nil
+	}
+	return &channel
+}
+
+func (r *devopsRepository) ListNotificationChannels(ctx context.Context) ([]devops.NotificationChannel, error) {
+	var channels []devops.NotificationChannel
+	err := r.db.WithContext(ctx).Order("id asc").Find(&channels).Error
+	return channels, err
+}
+
+// DeleteNotificationChannel also deletes the rules routing to the channel.
+func (r *devopsRepository) DeleteNotificationChannel(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("channel_id = ?", id).Delete(&devops.NotificationRule{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&devops.NotificationChannel{}, id).Error
+	})
+}
+
+func (r *devopsRepository) SaveNotificationRule(ctx context.Context, rule *devops.NotificationRule) error {
+	return r.db.WithContext(ctx).Save(rule).Error
+}
+
+func (r *devopsRepository) ListNotificationRules(ctx context.Context) ([]devops.NotificationRule, error) {
+	var rules []devops.NotificationRule
+	err := r.db.WithContext(ctx).Order("id asc").Find(&rules).Error
+	return rules, err
+}
+
+func (r *devopsRepository) ListEnabledNotificationRules(ctx context.Context, configID uint64) ([]devops.NotificationRule, error) {
+	var rules []devops.NotificationRule
+	err := r.db.WithContext(ctx).
+		Where("enabled = ? AND (config_id = 0 OR config_id = ?)", true, configID).
+		Order("id asc").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *devopsRepository) DeleteNotificationRule(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Delete(&devops.NotificationRule{}, id).Error
+}
+
+func (r *devopsRepository) CreateCommitStatusReport(ctx context.Context, report *devops.CommitStatusReport) error {
+	return r.db.WithContext(ctx).Create(report).Error
+}
+
+func (r *devopsRepository) ListCommitStatusReports(ctx context.Context, pipelineID uint64) ([]devops.CommitStatusReport, error) {
+	var reports []devops.CommitStatusReport
+	err := r.db.WithContext(ctx).Where("pipeline_id = ?", pipelineID).Order("id asc").Find(&reports).Error
+	return reports, err
+}
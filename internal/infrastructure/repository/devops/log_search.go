@@ -0,0 +1,149 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/domain/repository"
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	logSearchTable = "devops_pipeline_log_fts"
+
+	// The rowid of an indexed line is pipelineID*logLineStride + line, so
+	// that the lines of a pipeline are a rowid range. Later lines would run
+	// into the range of the next pipeline and aren't indexed.
+	logLineStride = 1 << 20
+
+	logIndexBatchSize = 500
+)
+
+// maxIndexedLines is the last line of a log that is indexed.
+var maxIndexedLines = logLineStride - 1
+
+// MigrateLogSearch creates the FTS5 table of the pipeline log search index.
+func MigrateLogSearch(db *gorm.DB) error {
+	return db.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS " + logSearchTable + " USING fts5(content)").Error
+}
+
+func (r *devopsRepository) IndexPipelineLog(ctx context.Context, pipelineID uint64, log string) (int, error) {
+	first := pipelineID * logLineStride
+	skipped := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deleteLogIndex(tx, pipelineID); err != nil {
+			return err
+		}
+
+		lines := strings.Split(log, "\n")
+		if len(lines) > maxIndexedLines {
+			for _, line := range lines[maxIndexedLines:] {
+				if strings.TrimSpace(line) != "" {
+					skipped++
+				}
+			}
+			lines = lines[:maxIndexedLines]
+		}
+		indexed := 0
+		values := make([]string, 0, logIndexBatchSize)
+		args := make([]interface{}, 0, 2*logIndexBatchSize)
+		flush := func() error {
+			if len(values) == 0 {
+				return nil
+			}
+			err := tx.Exec("INSERT INTO "+logSearchTable+" (rowid, content) VALUES "+strings.Join(values, ","), args...).Error
+			values, args = values[:0], args[:0]
+			return err
+		}
+		for i, line := range lines {
+			line = strings.TrimSuffix(line, "\r")
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			values = append(values, "(?, ?)")
+			args = append(args, first+uint64(i+1), line)
+			indexed++
+			if len(values) == logIndexBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+
+		index := &devops.PipelineLogIndex{PipelineID: pipelineID, Lines: indexed, IndexedAt: time.Now()}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(index).Error
+	})
+	return skipped, err
+}
+
+// deleteLogIndex removes the log of a pipeline from the search index.
+func deleteLogIndex(tx *gorm.DB, pipelineID uint64) error {
+	first := pipelineID * logLineStride
+	if err := tx.Exec("DELETE FROM "+logSearchTable+" WHERE rowid BETWEEN ? AND ?", first, first+logLineStride-1).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&devops.PipelineLogIndex{}, pipelineID).Error
+}
+
+func (r *devopsRepository) ListUnindexedPipelineIDs(ctx context.Context, statuses []string, limit int) ([]uint64, error) {
+	var ids []uint64
+	err := r.db.WithContext(ctx).Model(&devops.PipelineRecord{}).
+		Where("status IN ? AND log <> ''", statuses).
+		Where("id NOT IN (?)", r.db.Model(&devops.PipelineLogIndex{}).Select("pipeline_id")).
+		Order("id asc").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// SearchPipelineLogs returns one page of the pipelines with matching lines,
+// ordered by ID, and the total number of such pipelines.
+func (r *devopsRepository) SearchPipelineLogs(ctx context.Context, filter repository.LogSearchFilter) ([]repository.LogSearchMatch, int64, error) {
+	where := logSearchTable + " MATCH ?"
+	args := []interface{}{logLineStride, filter.Query}
+	if filter.ConfigID != 0 {
+		where += " AND p.config_id = ?"
+		args = append(args, filter.ConfigID)
+	}
+	// Timestamps are stored in server local time, compare in the same zone.
+	if filter.From != nil {
+		where += " AND p.created_at >= ?"
+		args = append(args, filter.From.Local())
+	}
+	if filter.To != nil {
+		where += " AND p.created_at < ?"
+		args = append(args, filter.To.Local())
+	}
+	query := "SELECT p.id AS pipeline_id, p.config_id, p.repo_name, p.status, p.ref, p.commit_sha, p.created_at, count(*) AS matches" +
+		" FROM " + logSearchTable + " f JOIN devops_pipeline_records p ON p.id = f.rowid / ?" +
+		" WHERE " + where + " GROUP BY p.id"
+
+	db := r.db.WithContext(ctx)
+	var total int64
+	if err := db.Raw("SELECT count(*) FROM ("+query+")", args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := " ORDER BY p.id asc"
+	if filter.SortDesc {
+		order = " ORDER BY p.id desc"
+	}
+	var matches []repository.LogSearchMatch
+	err := db.Raw(query+order+" LIMIT ? OFFSET ?", append(args, filter.Limit, filter.Offset)...).Scan(&matches).Error
+	return matches, total, err
+}
+
+func (r *devopsRepository) ListLogSearchHits(ctx context.Context, query string, pipelineID uint64, limit int) ([]repository.LogSearchHit, error) {
+	first := pipelineID * logLineStride
+	var hits []repository.LogSearchHit
+	err := r.db.WithContext(ctx).Raw(
+		"SELECT rowid % ? AS line, snippet("+logSearchTable+", 0, ?, ?, '...', 32) AS snippet FROM "+logSearchTable+
+			" WHERE "+logSearchTable+" MATCH ? AND rowid BETWEEN ? AND ? ORDER BY rowid LIMIT ?",
+		logLineStride, repository.MatchStart, repository.MatchEnd, query, first, first+logLineStride-1, limit,
+	).Scan(&hits).Error
+	return hits, err
+}
@@ -0,0 +1,75 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/domain/repository"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestRepository(t *testing.T) *devopsRepository {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "opsgo.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&devops.PipelineRecord{}, &devops.PipelineLogIndex{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateLogSearch(db); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return &devopsRepository{db: db}
+}
+
+func TestIndexPipelineLogLineLimit(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+	defer func(max int) { maxIndexedLines = max }(maxIndexedLines)
+	maxIndexedLines = 3
+
+	long := &devops.PipelineRecord{RepoName: "app", Status: "success"}
+	next := &devops.PipelineRecord{RepoName: "app", Status: "success"}
+	for _, record := range []*devops.PipelineRecord{long, next} {
+		if err := r.CreatePipelineRecord(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	skipped, err := r.IndexPipelineLog(ctx, long.ID, "a err\nb\n\nc\nd err\ne err\n")
+	if err != nil || skipped != 3 {
+		t.Errorf("IndexPipelineLog() = %d, %v, want 3 lines skipped", skipped, err)
+	}
+	if skipped, err := r.IndexPipelineLog(ctx, next.ID, "err here\n"); err != nil || skipped != 0 {
+		t.Errorf("IndexPipelineLog() = %d, %v, want no line skipped", skipped, err)
+	}
+
+	for _, record := range []*devops.PipelineRecord{long, next} {
+		hits, err := r.ListLogSearchHits(ctx, `"err"`, record.ID, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hits) != 1 || hits[0].Line != 1 {
+			t.Errorf("hits of pipeline %d: %+v, want line 1 only", record.ID, hits)
+		}
+	}
+	matches, total, err := r.SearchPipelineLogs(ctx, repository.LogSearchFilter{Query: `"err"`, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(matches) != 2 || matches[0].Matches != 1 || matches[1].Matches != 1 {
+		t.Errorf("search matches %+v (total %d), want one line in each pipeline", matches, total)
+	}
+}
@@ -0,0 +1,72 @@
+package devops
+
+import (
+	"OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/domain/repository"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+func (r *devopsRepository) ListRetentionCandidates(ctx context.Context, filter repository.RetentionFilter) ([]repository.RetentionCandidate, error) {
+	ranked := r.db.Model(&devops.PipelineRecord{}).Select(
+		"id, config_id, status, log_archive, log <> '' AS inline_log, created_at, " +
+			"length(CAST(log AS BLOB)) AS own_log_size, " +
+			"length(CAST(log AS BLOB)) + coalesce((SELECT sum(length(CAST(a.log AS BLOB))) FROM devops_pipeline_attempts a WHERE a.pipeline_id = devops_pipeline_records.id), 0) AS log_size, " +
+			"ROW_NUMBER() OVER (PARTITION BY config_id ORDER BY id DESC) AS position")
+
+	query := r.db.WithContext(ctx).Table("(?) AS ranked", ranked).
+		Select("id, config_id, log_archive, log_size, own_log_size, created_at").
+		Where("position > ? AND status IN ? AND id > ?", filter.KeepLast, filter.Statuses, filter.AfterID)
+	// Timestamps are stored in server local time, compare in the same zone.
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", filter.CreatedBefore.Local())
+	}
+	if filter.InlineLog {
+		query = query.Where("inline_log")
+	}
+	if filter.KeepActive {
+		query = query.Where("id NOT IN (?)", r.db.Model(&devops.Release{}).Select("pipeline_id").Where("active = ?", true))
+	}
+
+	var candidates []repository.RetentionCandidate
+	err := query.Order("id asc").Limit(filter.Limit).Scan(&candidates).Error
+	return candidates, err
+}
+
+func (r *devopsRepository) ArchivePipelineLog(ctx context.Context, pipelineID uint64, archive string) error {
+	return r.db.WithContext(ctx).Model(&devops.PipelineRecord{}).Where("id = ?", pipelineID).
+		Updates(map[string]interface{}{"log": "", "log_archive": archive}).Error
+}
+
+func (r *devopsRepository) DeletePipelineRecord(ctx context.Context, pipelineID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&devops.PipelineAttempt{}, &devops.PipelineArtifact{}, &devops.CommitStatusReport{}} {
+			if err := tx.Where("pipeline_id = ?", pipelineID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		// The active release is never purged with its pipeline.
+		if err := tx.Where("pipeline_id = ? AND active = ?", pipelineID, false).Delete(&devops.Release{}).Error; err != nil {
+			return err
+		}
+		if err := deleteLogIndex(tx, pipelineID); err != nil {
+			return err
+		}
+		return tx.Delete(&devops.PipelineRecord{}, pipelineID).Error
+	})
+}
+
+func (r *devopsRepository) Vacuum(ctx context.Context) error {
+	return r.db.WithContext(ctx).Exec("VACUUM").Error
+}
+
+func (r *devopsRepository) CreateRetentionRun(ctx context.Context, run *devops.RetentionRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+func (r *devopsRepository) ListRetentionRuns(ctx context.Context, limit int) ([]devops.RetentionRun, error) {
+	var runs []devops.RetentionRun
+	err := r.db.WithContext(ctx).Order("id desc").Limit(limit).Find(&runs).Error
+	return runs, err
+}
@@ -0,0 +1,90 @@
+package devops
+
+import (
+	"OpsGo/internal/application/service/devops"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadArtifact is called by deploy scripts with the token from
+// OPSGO_PIPELINE_TOKEN in the X-Pipeline-Token header.
+func (h *DevOpsHandler) UploadArtifact(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file"})
+		return
+	}
+	name := c.PostForm("name")
+	if name == "" {
+		name = path.Base(file.Filename)
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	artifact, err := h.devopsService.UploadArtifact(c.Request.Context(), id, c.GetHeader("X-Pipeline-Token"), name, f)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, devops.ErrInvalidPipelineToken) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": artifact})
+}
+
+func (h *DevOpsHandler) ListArtifacts(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	artifacts, err := h.devopsService.ListArtifacts(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": artifacts})
+}
+
+func (h *DevOpsHandler) DownloadArtifact(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	artifactID, err := strconv.ParseUint(c.Param("artifact_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	artifact, filePath, err := h.devopsService.GetArtifactFile(c.Request.Context(), id, artifactID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("X-Checksum-Sha256", artifact.SHA256)
+	c.FileAttachment(filePath, path.Base(artifact.Name))
+}
@@ -0,0 +1,36 @@
+package devops
+
+import (
+	"OpsGo/internal/application/service/devops"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommitStatusHandler struct {
+	commitStatusService *devops.CommitStatusService
+}
+
+func NewCommitStatusHandler(commitStatusService *devops.CommitStatusService) *CommitStatusHandler {
+	return &CommitStatusHandler{
+		commitStatusService: commitStatusService,
+	}
+}
+
+func (h *CommitStatusHandler) ListReports(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	reports, err := h.commitStatusService.ListReports(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": reports})
+}
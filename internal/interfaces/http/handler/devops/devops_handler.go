@@ -3,6 +3,9 @@ package devops
 import (
 	"OpsGo/internal/application/dto"
 	"OpsGo/internal/application/service/devops"
+	devopsEntity "OpsGo/internal/domain/entity/devops"
+	"OpsGo/internal/interfaces/http/middleware"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -35,6 +38,30 @@ func (h *DevOpsHandler) ConfigRepo(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": resp})
 }
 
+// SaveForgeSettings configures commit status reporting of a service (admin only).
+func (h *DevOpsHandler) SaveForgeSettings(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req dto.ForgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	resp, err := h.devopsService.SaveForgeSettings(c.Request.Context(), id, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
 func (h *DevOpsHandler) DeleteConfig(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
@@ -61,6 +88,26 @@ func (h *DevOpsHandler) GetSummary(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": resp})
 }
 
+func (h *DevOpsHandler) ListPipelines(c *gin.Context) {
+	var req dto.PipelineListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	pipelines, total, err := h.devopsService.ListPipelines(c.Request.Context(), req)
+	if errors.Is(err, devops.ErrInvalidDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessWithPage(pipelines, req.Page, req.PageSize, total))
+}
+
 func (h *DevOpsHandler) GetServiceLog(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
@@ -69,15 +116,37 @@ func (h *DevOpsHandler) GetServiceLog(c *gin.Context) {
 		return
 	}
 
-	content, err := h.devopsService.GetServiceLog(c.Request.Context(), id)
+	var req dto.ServiceLogRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	resp, err := h.devopsService.GetServiceLog(c.Request.Context(), id, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": content})
+	c.JSON(http.StatusOK, gin.H{"data": resp})
 }
 
+func (h *DevOpsHandler) ListLogSources(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	sources, err := h.devopsService.ListLogSources(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sources})
+}
 
 func (h *DevOpsHandler) HandleCICallback(c *gin.Context) {
 	var req dto.CICallbackRequest
@@ -86,7 +155,10 @@ func (h *DevOpsHandler) HandleCICallback(c *gin.Context) {
 		return
 	}
 
-	if err := h.devopsService.HandleCICallback(c.Request.Context(), req); err != nil {
+	if err := h.devopsService.HandleCICallback(c.Request.Context(), req); errors.Is(err, devops.ErrDeployFrozen) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -95,17 +167,149 @@ func (h *DevOpsHandler) HandleCICallback(c *gin.Context) {
 }
 
 func (h *DevOpsHandler) TriggerDeployment(c *gin.Context) {
-	var req struct {
-		ConfigID uint64 `json:"config_id" binding:"required"`
+	var req dto.TriggerDeploymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	user := c.GetString(middleware.ContextUserKey)
+	record, err := h.devopsService.TriggerDeployment(c.Request.Context(), req, user)
+	h.respondPipelineTriggered(c, record, err, "Deployment triggered")
+}
+
+// TriggerDeploymentOverride deploys through active freeze windows (admin only).
+func (h *DevOpsHandler) TriggerDeploymentOverride(c *gin.Context) {
+	var req dto.OverrideDeploymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	user := c.GetString(middleware.ContextUserKey)
+	record, err := h.devopsService.TriggerDeploymentOverride(c.Request.Context(), req, user)
+	h.respondPipelineTriggered(c, record, err, "Deployment triggered with freeze override")
+}
+
+func (h *DevOpsHandler) respondPipelineTriggered(c *gin.Context, record *devopsEntity.PipelineRecord, err error, message string) {
+	if errors.Is(err, devops.ErrDeployFrozen) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, devops.ErrInvalidEnv) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, devops.ErrPipelineNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, devops.ErrPipelineStatus) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if record.Status == "queued" {
+		message = "Deployment queued until the freeze window ends"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "data": gin.H{"pipeline_id": record.ID, "status": record.Status}})
+}
+
+func (h *DevOpsHandler) RerunPipeline(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
 	}
+
+	user := c.GetString(middleware.ContextUserKey)
+	record, err := h.devopsService.RerunPipeline(c.Request.Context(), id, user)
+	h.respondPipelineTriggered(c, record, err, "Pipeline re-run triggered")
+}
+
+func (h *DevOpsHandler) RollbackToPipeline(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	user := c.GetString(middleware.ContextUserKey)
+	record, err := h.devopsService.RollbackToPipeline(c.Request.Context(), id, user)
+	h.respondPipelineTriggered(c, record, err, "Rollback triggered")
+}
+
+func (h *DevOpsHandler) GetPipeline(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	resp, err := h.devopsService.GetPipelineDetail(c.Request.Context(), id)
+	if errors.Is(err, devops.ErrPipelineNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+func (h *DevOpsHandler) ApprovePipeline(c *gin.Context) {
+	h.decideApproval(c, true)
+}
+
+func (h *DevOpsHandler) RejectPipeline(c *gin.Context) {
+	h.decideApproval(c, false)
+}
+
+func (h *DevOpsHandler) decideApproval(c *gin.Context, approve bool) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req dto.ApprovalDecisionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
 		return
 	}
 
-	if err := h.devopsService.TriggerDeployment(c.Request.Context(), req.ConfigID); err != nil {
+	user := c.GetString(middleware.ContextUserKey)
+	if approve {
+		err = h.devopsService.ApprovePipeline(c.Request.Context(), id, user, req.Comment)
+	} else {
+		err = h.devopsService.RejectPipeline(c.Request.Context(), id, user, req.Comment)
+	}
+	if errors.Is(err, devops.ErrPipelineNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, devops.ErrPipelineStatus) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Deployment triggered"})
+
+	if approve {
+		c.JSON(http.StatusOK, gin.H{"message": "Pipeline approved"})
+	} else {
+		c.JSON(http.StatusOK, gin.H{"message": "Pipeline rejected"})
+	}
 }
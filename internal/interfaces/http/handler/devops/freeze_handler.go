@@ -0,0 +1,51 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *DevOpsHandler) ListFreezeWindows(c *gin.Context) {
+	windows, err := h.devopsService.ListFreezeWindows(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": windows})
+}
+
+func (h *DevOpsHandler) SaveFreezeWindow(c *gin.Context) {
+	var req dto.FreezeWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	window, err := h.devopsService.SaveFreezeWindow(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": window})
+}
+
+func (h *DevOpsHandler) DeleteFreezeWindow(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := h.devopsService.DeleteFreezeWindow(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Freeze window deleted successfully"})
+}
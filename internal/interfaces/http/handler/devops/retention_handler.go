@@ -0,0 +1,42 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/application/service/devops"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *DevOpsHandler) ListRetentionRuns(c *gin.Context) {
+	runs, err := h.devopsService.ListRetentionRuns(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": runs})
+}
+
+func (h *DevOpsHandler) RunRetention(c *gin.Context) {
+	var req dto.RetentionRunRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+			return
+		}
+	}
+
+	run, err := h.devopsService.RunRetention(c.Request.Context(), req.DryRun)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, devops.ErrRetentionRunning) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": run})
+}
@@ -0,0 +1,35 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/application/service/devops"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	logSearchService *devops.LogSearchService
+}
+
+func NewSearchHandler(logSearchService *devops.LogSearchService) *SearchHandler {
+	return &SearchHandler{
+		logSearchService: logSearchService,
+	}
+}
+
+func (h *SearchHandler) SearchLogs(c *gin.Context) {
+	var req dto.LogSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	results, total, err := h.logSearchService.Search(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessWithPage(results, req.Page, req.PageSize, total))
+}
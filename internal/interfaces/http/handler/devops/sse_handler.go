@@ -1,6 +1,9 @@
 package devops
 
 import (
+	"OpsGo/internal/application/dto"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -46,3 +49,54 @@ func (h *DevOpsHandler) StreamLogs(c *gin.Context) {
 		}
 	}
 }
+
+// StreamServiceLog follows a runtime log of a service like tail -F. Lines are
+// sent as "line" events; "truncated" and "rotated" events tell the client that
+// a file was truncated or replaced.
+func (h *DevOpsHandler) StreamServiceLog(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req dto.ServiceLogStreamRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	events, err := h.devopsService.FollowServiceLog(ctx, id, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no") // Disable Nginx buffering
+	c.SSEvent("ping", "connected")
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			c.SSEvent(event.Type, event)
+			c.Writer.Flush()
+		case <-ticker.C:
+			c.Writer.Write([]byte(": keep-alive\n\n"))
+			c.Writer.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/application/service/devops"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatsHandler struct {
+	statsService *devops.StatsService
+}
+
+func NewStatsHandler(statsService *devops.StatsService) *StatsHandler {
+	return &StatsHandler{
+		statsService: statsService,
+	}
+}
+
+func (h *StatsHandler) GetStats(c *gin.Context) {
+	var req dto.StatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request parameters"})
+		return
+	}
+
+	resp, err := h.statsService.GetStats(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
@@ -0,0 +1,260 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/application/service/devops"
+	"OpsGo/internal/infrastructure/config"
+	"OpsGo/internal/interfaces/http/middleware"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsPingInterval = 15 * time.Second
+	wsWriteTimeout = 10 * time.Second
+)
+
+var upgrader = websocket.Upgrader{CheckOrigin: checkOrigin}
+
+// checkOrigin accepts clients without an Origin (not browsers), the same
+// origin and the origins in server.allowed_origins. Pages elsewhere must not
+// act on pipelines with the credentials of whoever visits them.
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || slices.Contains(config.AppConfig.Server.AllowedOrigins, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// wsFilter holds the pipelines a WebSocket client subscribed to. Until the
+// first subscribe the client receives the events of every pipeline.
+type wsFilter struct {
+	mu        sync.Mutex
+	pipelines map[uint64]bool
+}
+
+func (f *wsFilter) update(ids []uint64, subscribe bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.pipelines == nil {
+		f.pipelines = make(map[uint64]bool)
+	}
+	for _, id := range ids {
+		if subscribe {
+			f.pipelines[id] = true
+		} else {
+			delete(f.pipelines, id)
+		}
+	}
+}
+
+func (f *wsFilter) allows(pipelineID uint64) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pipelines == nil || f.pipelines[pipelineID]
+}
+
+// seqRange is a run of consecutive event sequence numbers.
+type seqRange struct{ first, last uint64 }
+
+// seqSet holds the sequence numbers of the events sent for a pipeline, as
+// sorted disjoint ranges. Live events arrive in order, so it stays small
+// unless gaps or unsubscriptions leave holes.
+type seqSet struct {
+	ranges []seqRange
+}
+
+func (s *seqSet) contains(seq uint64) bool {
+	i := sort.Search(len(s.ranges), func(i int) bool { return s.ranges[i].last >= seq })
+	return i < len(s.ranges) && s.ranges[i].first <= seq
+}
+
+// add inserts seq, merging the ranges it adjoins.
+func (s *seqSet) add(seq uint64) {
+	// The first range ending at or right before seq.
+	i := sort.Search(len(s.ranges), func(i int) bool { return s.ranges[i].last+1 >= seq })
+	switch {
+	case i == len(s.ranges) || s.ranges[i].first > seq+1:
+		s.ranges = slices.Insert(s.ranges, i, seqRange{seq, seq})
+	case seq+1 == s.ranges[i].first:
+		s.ranges[i].first = seq
+	case seq == s.ranges[i].last+1:
+		s.ranges[i].last = seq
+		if i+1 < len(s.ranges) && s.ranges[i+1].first == seq+1 {
+			s.ranges[i].last = s.ranges[i+1].last
+			s.ranges = slices.Delete(s.ranges, i+1, i+2)
+		}
+	}
+}
+
+// StreamWebSocket carries the StreamLogs events over a WebSocket, for proxies
+// that buffer SSE, and accepts commands from the client on the same
+// connection.
+func (h *DevOpsHandler) StreamWebSocket(c *gin.Context) {
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // Upgrade already replied with an error
+	}
+	defer conn.Close()
+
+	clientChan := h.devopsService.Broadcaster.Register()
+	defer h.devopsService.Broadcaster.Unregister(clientChan)
+
+	cmds := &wsCommands{
+		ctx:     c.Request.Context(),
+		user:    c.GetString(middleware.ContextUserKey),
+		filter:  &wsFilter{},
+		replies: make(chan []any, 16),
+		closed:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	defer close(cmds.done)
+	go h.readCommands(conn, cmds)
+
+	// Events already sent per pipeline, so that live events and replays
+	// overlapping each other are not delivered twice.
+	sent := make(map[uint64]*seqSet)
+	firstSend := func(event devops.LogEvent) bool {
+		if event.Type == "gap" {
+			return true
+		}
+		seqs := sent[event.PipelineID]
+		if seqs == nil {
+			seqs = &seqSet{}
+			sent[event.PipelineID] = seqs
+		}
+		if seqs.contains(event.Seq) {
+			return false
+		}
+		seqs.add(event.Seq)
+		return true
+	}
+	write := func(msg any) bool {
+		conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return conn.WriteJSON(msg) == nil
+	}
+
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-clientChan:
+			if !ok {
+				return
+			}
+			if !cmds.filter.allows(event.PipelineID) {
+				continue
+			}
+			if !firstSend(event) {
+				continue
+			}
+			if !write(event) {
+				return
+			}
+		case msgs := <-cmds.replies:
+			for _, msg := range msgs {
+				if event, ok := msg.(devops.LogEvent); ok && !firstSend(event) {
+					continue
+				}
+				if !write(msg) {
+					return
+				}
+			}
+		case <-ticker.C:
+			if conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)) != nil {
+				return
+			}
+		case <-cmds.closed:
+			return
+		}
+	}
+}
+
+// wsCommands is the state shared by a WebSocket connection and its reader.
+type wsCommands struct {
+	ctx     context.Context
+	user    string // authenticated user, only used by the reader
+	filter  *wsFilter
+	replies chan []any    // messages for the writer, in order
+	closed  chan struct{} // closed by the reader when the client went away
+	done    chan struct{} // closed by the writer when it stopped
+}
+
+// readCommands executes client commands until the connection is closed. Its
+// replies are written by StreamWebSocket, the only writer of the connection.
+func (h *DevOpsHandler) readCommands(conn *websocket.Conn, cmds *wsCommands) {
+	defer close(cmds.closed)
+
+	conn.SetReadDeadline(time.Now().Add(2 * wsPingInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * wsPingInterval))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var cmd dto.WSCommand
+		var msgs []any
+		reply := dto.WSReply{Type: "reply"}
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			reply.Error = "Invalid command"
+		} else {
+			reply.Action = cmd.Action
+			reply.PipelineID = cmd.PipelineID
+			msgs, reply.Error = h.runCommand(cmds, cmd)
+		}
+
+		select {
+		case cmds.replies <- append(msgs, reply):
+		case <-cmds.done:
+			return
+		}
+	}
+}
+
+// runCommand returns the events to send before the reply, and the error of
+// the command if it failed.
+func (h *DevOpsHandler) runCommand(cmds *wsCommands, cmd dto.WSCommand) ([]any, string) {
+	switch cmd.Action {
+	case "auth":
+		user := middleware.UserByToken(cmd.Token)
+		if user == nil {
+			return nil, "Invalid token"
+		}
+		cmds.user = user.Name
+	case "subscribe", "unsubscribe":
+		cmds.filter.update(cmd.PipelineIDs, cmd.Action == "subscribe")
+	case "cancel":
+		if cmds.user == "" {
+			return nil, "Authentication required"
+		}
+		if err := h.devopsService.CancelPipeline(cmds.ctx, cmd.PipelineID, cmds.user); err != nil {
+			return nil, err.Error()
+		}
+	case "replay":
+		var msgs []any
+		for _, event := range h.devopsService.Broadcaster.Replay(cmd.PipelineID, cmd.Since) {
+			msgs = append(msgs, event)
+		}
+		return msgs, ""
+	default:
+		return nil, "Unknown action"
+	}
+	return nil, ""
+}
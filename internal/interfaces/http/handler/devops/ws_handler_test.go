@@ -0,0 +1,110 @@
+package devops
+
+import (
+	"OpsGo/internal/application/dto"
+	"OpsGo/internal/application/service/devops"
+	"OpsGo/internal/infrastructure/config"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func TestSeqSet(t *testing.T) {
+	var s seqSet
+	for _, seq := range []uint64{1, 2, 3, 7, 5, 6, 10, 4} {
+		s.add(seq)
+	}
+	want := []seqRange{{1, 7}, {10, 10}}
+	if !slices.Equal(s.ranges, want) {
+		t.Errorf("ranges %v, want %v", s.ranges, want)
+	}
+	for seq, in := range map[uint64]bool{0: false, 1: true, 4: true, 7: true, 8: false, 9: false, 10: true, 11: false} {
+		if s.contains(seq) != in {
+			t.Errorf("contains(%d) = %v, want %v", seq, !in, in)
+		}
+	}
+}
+
+// wsClient reads the messages of a test WebSocket connection.
+type wsClient struct {
+	t    *testing.T
+	conn *websocket.Conn
+}
+
+func (c *wsClient) send(cmd dto.WSCommand) {
+	c.t.Helper()
+	if err := c.conn.WriteJSON(cmd); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// next returns the type and pipeline log content or reply action of the
+// next message.
+func (c *wsClient) next() string {
+	c.t.Helper()
+	var msg struct {
+		devops.LogEvent
+		Action string `json:"action"`
+		Error  string `json:"error"`
+	}
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := c.conn.ReadJSON(&msg); err != nil {
+		c.t.Fatal(err)
+	}
+	if msg.Type == "reply" {
+		return "reply " + msg.Action + msg.Error
+	}
+	return msg.Type + " " + strings.TrimSpace(msg.Content)
+}
+
+func TestStreamWebSocketReplaySkipsSentEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.AppConfig = &config.Config{}
+	config.AppConfig.Artifacts.Dir = t.TempDir()
+	service := devops.NewDevOpsService(nil)
+	r := gin.New()
+	r.GET("/ws", NewDevOpsHandler(service).StreamWebSocket)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := &wsClient{t: t, conn: conn}
+	expect := func(want ...string) {
+		t.Helper()
+		for _, w := range want {
+			if got := c.next(); got != w {
+				t.Fatalf("got %q, want %q", got, w)
+			}
+		}
+	}
+	broadcast := service.Broadcaster.BroadcastLog
+
+	c.send(dto.WSCommand{Action: "subscribe", PipelineIDs: []uint64{7, 9}})
+	expect("reply subscribe")
+	broadcast(7, "a\n")
+	broadcast(7, "b\n")
+	expect("log a", "log b")
+
+	// c is not delivered live; x marks that it went past the filter.
+	c.send(dto.WSCommand{Action: "unsubscribe", PipelineIDs: []uint64{7}})
+	expect("reply unsubscribe")
+	broadcast(7, "c\n")
+	broadcast(9, "x\n")
+	expect("log x")
+	c.send(dto.WSCommand{Action: "subscribe", PipelineIDs: []uint64{7}})
+	expect("reply subscribe")
+	broadcast(7, "d\n")
+	expect("log d")
+
+	c.send(dto.WSCommand{Action: "replay", PipelineID: 7})
+	expect("log c", "reply replay")
+}
@@ -1,12 +1,22 @@
 package middleware
 
 import (
+	"OpsGo/internal/infrastructure/config"
+	"crypto/subtle"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// ContextUserKey 认证成功后用户名在 gin.Context 中的键
+	ContextUserKey = "auth_user"
+	// ContextRolesKey 认证成功后角色列表在 gin.Context 中的键
+	ContextRolesKey = "auth_roles"
+)
+
 // Auth 简单的认证中间件 (OpsGo 独立版本)
 func Auth() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -25,8 +35,63 @@ func Auth() gin.HandlerFunc {
 		}
 
 		// TODO: 在独立项目中校验 JWT。如果不方便同步 Secret，可以暂时先透传或使用统一 Secret。
-		// 这里由于是内部工具，暂时放行，或者你可以后续把 JWT 逻辑也搬过来。
+		// 目前支持 config.yaml 中配置的静态 Token；未配置用户时保持放行。
+		if len(config.AppConfig.Auth.Users) > 0 && !identify(c, parts[1]) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// OptionalAuth 识别携带有效 Token 的用户，但不拒绝匿名请求
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			identify(c, token)
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole 要求已认证用户拥有指定角色，需放在 Auth 之后
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roles := c.GetStringSlice(ContextRolesKey)
+		if !slices.Contains(roles, role) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Role " + role + " is required"})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
 }
+
+// identify 将 Token 对应的用户写入上下文
+func identify(c *gin.Context, token string) bool {
+	user := findUser(config.AppConfig.Auth.Users, token)
+	if user == nil {
+		return false
+	}
+	c.Set(ContextUserKey, user.Name)
+	c.Set(ContextRolesKey, user.Roles)
+	return true
+}
+
+// UserByToken 返回 Token 对应的用户，供无法携带 Authorization 头的连接（如浏览器 WebSocket）认证
+func UserByToken(token string) *config.AuthUser {
+	return findUser(config.AppConfig.Auth.Users, token)
+}
+
+func findUser(users []config.AuthUser, token string) *config.AuthUser {
+	for i := range users {
+		if users[i].Token != "" && subtle.ConstantTimeCompare([]byte(users[i].Token), []byte(token)) == 1 {
+			return &users[i]
+		}
+	}
+	return nil
+}